@@ -0,0 +1,233 @@
+package gosendcrypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/payourse/gosendcrypto/hdwallet"
+)
+
+const bitcoinMessageMagic = "Bitcoin Signed Message:\n"
+
+// BIP-137 header bytes start at 27; +4 marks a compressed key, +8 a P2SH
+// wrapped segwit address and +12 a native segwit address.
+const (
+	bip137HeaderBase         = 27
+	bip137HeaderCompressed   = 31
+	bip137HeaderNestedSegwit = 35
+	bip137HeaderSegwit       = 39
+)
+
+// SignMessageBIP322 signs message for the key's P2WPKH address using the
+// BIP-322 simple format, returning it base64 encoded.
+func (c *CryptoSender) SignMessageBIP322(privateKey, message string) (string, error) {
+	if c.blockchain != Blockchain.Bitcoin {
+		return "", errors.New("BIP-322 signing is only supported on bitcoin")
+	}
+
+	wif, err := btcutil.DecodeWIF(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := hdwallet.BitcoinAddress(wif.PrivKey.PubKey(), hdwallet.BIP84, networks[string(c.network)])
+	if err != nil {
+		return "", err
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+
+	toSign, err := bip322ToSign(pkScript, message)
+	if err != nil {
+		return "", err
+	}
+
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, prevFetcher)
+	witness, err := txscript.WitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashAll, wif.PrivKey, true)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+		return "", err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return "", err
+		}
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func signBitcoinMessage(privateKey, message string) (string, error) {
+	wif, err := btcutil.DecodeWIF(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := bitcoinMessageHash(message)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := ecdsa.SignCompact(wif.PrivKey, hash, true)
+	if err != nil {
+		return "", err
+	}
+	// our keys sign for P2WPKH addresses
+	sig[0] += bip137HeaderSegwit - bip137HeaderCompressed
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (c *CryptoSender) verifyBitcoinMessage(address, message, signature string) (bool, error) {
+	addr, err := btcutil.DecodeAddress(address, networks[string(c.network)])
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+
+	if len(sig) == 65 && sig[0] >= bip137HeaderBase && sig[0] < bip137HeaderSegwit+4 {
+		return verifyBIP137(addr, message, sig)
+	}
+	return verifyBIP322(addr, message, sig)
+}
+
+func verifyBIP137(addr btcutil.Address, message string, sig []byte) (bool, error) {
+	hash, err := bitcoinMessageHash(message)
+	if err != nil {
+		return false, err
+	}
+
+	// RecoverCompact only understands the P2PKH header range
+	compact := append([]byte{}, sig...)
+	if compact[0] >= bip137HeaderSegwit {
+		compact[0] -= bip137HeaderSegwit - bip137HeaderCompressed
+	} else if compact[0] >= bip137HeaderNestedSegwit {
+		compact[0] -= bip137HeaderNestedSegwit - bip137HeaderCompressed
+	}
+
+	pub, compressed, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return false, err
+	}
+
+	pubBytes := pub.SerializeUncompressed()
+	if compressed {
+		pubBytes = pub.SerializeCompressed()
+	}
+	pubKeyHash := btcutil.Hash160(pubBytes)
+
+	switch a := addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return bytes.Equal(a.Hash160()[:], pubKeyHash), nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return compressed && bytes.Equal(a.Hash160()[:], pubKeyHash), nil
+	case *btcutil.AddressScriptHash:
+		redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(pubKeyHash).Script()
+		if err != nil {
+			return false, err
+		}
+		return compressed && bytes.Equal(a.Hash160()[:], btcutil.Hash160(redeemScript)), nil
+	}
+	return false, errors.New("BIP-137 does not support address " + addr.EncodeAddress())
+}
+
+func verifyBIP322(addr btcutil.Address, message string, sig []byte) (bool, error) {
+	r := bytes.NewReader(sig)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return false, err
+	}
+	if count > uint64(len(sig)) {
+		return false, errors.New("invalid BIP-322 signature")
+	}
+	witness := make(wire.TxWitness, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(r, 0, uint32(len(sig)), "witness item")
+		if err != nil {
+			return false, err
+		}
+		witness = append(witness, item)
+	}
+	if r.Len() != 0 {
+		return false, errors.New("invalid BIP-322 signature")
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return false, err
+	}
+
+	toSign, err := bip322ToSign(pkScript, message)
+	if err != nil {
+		return false, err
+	}
+	toSign.TxIn[0].Witness = witness
+
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	vm, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(toSign, prevFetcher), 0, prevFetcher)
+	if err != nil {
+		return false, err
+	}
+	if err := vm.Execute(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// bip322ToSign builds the unsigned to_sign transaction spending the virtual
+// to_spend output that commits to message, see BIP-322.
+func bip322ToSign(pkScript []byte, message string) (*wire.MsgTx, error) {
+	messageHash := chainhash.TaggedHash([]byte("BIP0322-signed-message"), []byte(message))
+	scriptSig, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(messageHash[:]).Script()
+	if err != nil {
+		return nil, err
+	}
+
+	toSpend := wire.NewMsgTx(0)
+	toSpend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  scriptSig,
+		Sequence:         0,
+	})
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	opReturn, err := txscript.NewScriptBuilder().AddOp(txscript.OP_RETURN).Script()
+	if err != nil {
+		return nil, err
+	}
+
+	toSign := wire.NewMsgTx(0)
+	toSign.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: toSpend.TxHash(), Index: 0},
+		Sequence:         0,
+	})
+	toSign.AddTxOut(wire.NewTxOut(0, opReturn))
+	return toSign, nil
+}
+
+func bitcoinMessageHash(message string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarString(&buf, 0, bitcoinMessageMagic); err != nil {
+		return nil, err
+	}
+	if err := wire.WriteVarString(&buf, 0, message); err != nil {
+		return nil, err
+	}
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
@@ -0,0 +1,115 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+
+	"github.com/payourse/gosendcrypto/hdwallet"
+)
+
+const defaultGapLimit = 20
+
+type XpubAddress struct {
+	Address string
+	Change  uint32
+	Index   uint32
+	TxCount int
+}
+
+type XpubScanResult struct {
+	Used             []*XpubAddress
+	NextReceiveIndex uint32
+	NextChangeIndex  uint32
+	NextAddress      string
+}
+
+// DeriveXpubAddresses returns count receive (change=0) or change (change=1)
+// addresses of an xpub/ypub/zpub starting at index start.
+func (c *CryptoSender) DeriveXpubAddresses(xpub string, change, start, count uint32) ([]string, error) {
+	watchOnly, err := c.parseXpub(xpub)
+	if err != nil {
+		return nil, err
+	}
+	return watchOnly.Addresses(change, start, count)
+}
+
+// ScanXpub walks both the receive and change chains of an extended public key,
+// asking the gateway for the history of each address until gapLimit
+// consecutive unused addresses are found. NextAddress is the first unused
+// receive address, suitable for handing out as a deposit address.
+func (c *CryptoSender) ScanXpub(ctx context.Context, xpub string, gapLimit int) (*XpubScanResult, error) {
+	watchOnly, err := c.parseXpub(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	if gapLimit <= 0 {
+		gapLimit = defaultGapLimit
+	}
+
+	res := &XpubScanResult{
+		Used: []*XpubAddress{},
+	}
+
+	for change := uint32(0); change <= 1; change++ {
+		next := uint32(0)
+		gap := 0
+		for index := uint32(0); gap < gapLimit; index++ {
+			addr, err := watchOnly.Address(change, index)
+			if err != nil {
+				return nil, err
+			}
+
+			var history []struct {
+				TxHash string `json:"tx_hash"`
+				Height int    `json:"height"`
+			}
+			err = electrumCall(ctx, c.gateway, "getaddresshistory", &history, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(history) == 0 {
+				gap++
+				continue
+			}
+
+			gap = 0
+			next = index + 1
+			res.Used = append(res.Used, &XpubAddress{
+				Address: addr,
+				Change:  change,
+				Index:   index,
+				TxCount: len(history),
+			})
+		}
+
+		if change == 0 {
+			res.NextReceiveIndex = next
+		} else {
+			res.NextChangeIndex = next
+		}
+	}
+
+	res.NextAddress, err = watchOnly.Address(0, res.NextReceiveIndex)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *CryptoSender) parseXpub(xpub string) (*hdwallet.WatchOnly, error) {
+	if c.blockchain != Blockchain.Bitcoin {
+		return nil, errors.New("xpub addresses are only supported for bitcoin")
+	}
+
+	watchOnly, err := hdwallet.ParseExtendedPublicKey(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	if watchOnly.Net().Name != networks[string(c.network)].Name {
+		return nil, errors.New("xpub does not belong to the sender network")
+	}
+	return watchOnly, nil
+}
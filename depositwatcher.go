@@ -0,0 +1,321 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/payourse/gosendcrypto/erc20"
+)
+
+const (
+	defaultDepositConfirmations = 12
+	defaultDepositBatchSize     = 2000
+	defaultDepositPollInterval  = 15 * time.Second
+)
+
+// Deposit is an incoming transfer to a watched address. ContractAddress is
+// empty for native coin deposits. For native deposits LogIndex is 0 for the
+// transaction's own value and numbers the internal transfers of the
+// transaction from 1, so TxHash and LogIndex identify a deposit either way.
+type Deposit struct {
+	Blockchain      BlockchainEnum
+	TxHash          string
+	LogIndex        uint
+	BlockNumber     uint64
+	BlockHash       string
+	From            string
+	To              string
+	ContractAddress string
+	Amount          float64
+	RawAmount       *big.Int
+	Confirmations   uint64
+	Internal        bool
+}
+
+// ERC20DepositWatcher reports Transfer events of a set of token contracts
+// into a set of watched addresses. Deposits are only reported once they are
+// Confirmations blocks deep, so reorgs shallower than that never surface, and
+// each log is checked against the canonical block hash before it is reported.
+type ERC20DepositWatcher struct {
+	blockchain    BlockchainEnum
+	gateway       string
+	addresses     []common.Address
+	tokens        []common.Address
+	confirmations uint64
+	batchSize     uint64
+	pollInterval  time.Duration
+	lastBlock     uint64
+}
+
+func NewERC20DepositWatcher(blockchain BlockchainEnum, gatewayURL string, addresses, tokenContracts []string) *ERC20DepositWatcher {
+	w := &ERC20DepositWatcher{
+		blockchain:    blockchain,
+		gateway:       gatewayURL,
+		confirmations: defaultDepositConfirmations,
+		batchSize:     defaultDepositBatchSize,
+		pollInterval:  defaultDepositPollInterval,
+	}
+	for _, addr := range addresses {
+		w.addresses = append(w.addresses, common.HexToAddress(addr))
+	}
+	for _, token := range tokenContracts {
+		w.tokens = append(w.tokens, common.HexToAddress(token))
+	}
+	return w
+}
+
+func (w *ERC20DepositWatcher) SetConfirmations(confirmations uint64) *ERC20DepositWatcher {
+	w.confirmations = confirmations
+	return w
+}
+func (w *ERC20DepositWatcher) SetBatchSize(blocks uint64) *ERC20DepositWatcher {
+	w.batchSize = blocks
+	return w
+}
+func (w *ERC20DepositWatcher) SetPollInterval(interval time.Duration) *ERC20DepositWatcher {
+	w.pollInterval = interval
+	return w
+}
+
+// LastBlock is the last block whose deposits were fully reported; resume
+// from LastBlock()+1 after a restart.
+func (w *ERC20DepositWatcher) LastBlock() uint64 {
+	return atomic.LoadUint64(&w.lastBlock)
+}
+
+// Backfill reports deposits in [fromBlock, toBlock], scanning batchSize
+// blocks per log query. toBlock is lowered to the last confirmed block.
+func (w *ERC20DepositWatcher) Backfill(ctx context.Context, fromBlock, toBlock uint64, handler func(*Deposit) error) error {
+	client, err := ethclient.DialContext(ctx, w.gateway)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	decimals, err := w.tokenDecimals(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	confirmed, ok := confirmedHead(head, w.confirmations)
+	if !ok {
+		return nil
+	}
+	if toBlock > confirmed {
+		toBlock = confirmed
+	}
+	return w.scan(ctx, client, decimals, fromBlock, toBlock, head, handler)
+}
+
+// Watch backfills from fromBlock and then follows new heads until ctx is
+// cancelled, using a subscription on websocket gateways and polling
+// otherwise.
+func (w *ERC20DepositWatcher) Watch(ctx context.Context, fromBlock uint64, handler func(*Deposit) error) error {
+	client, err := ethclient.DialContext(ctx, w.gateway)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	decimals, err := w.tokenDecimals(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	next := fromBlock
+	return followHeads(ctx, client, w.gateway, w.pollInterval, func(head uint64) error {
+		confirmed, ok := confirmedHead(head, w.confirmations)
+		if !ok || confirmed < next {
+			return nil
+		}
+
+		err := w.scan(ctx, client, decimals, next, confirmed, head, handler)
+		if err != nil {
+			return err
+		}
+		next = confirmed + 1
+		return nil
+	})
+}
+
+func (w *ERC20DepositWatcher) scan(ctx context.Context, client *ethclient.Client, decimals map[common.Address]uint8, fromBlock, toBlock, head uint64, handler func(*Deposit) error) error {
+	batchSize := w.batchSize
+	if batchSize == 0 {
+		batchSize = defaultDepositBatchSize
+	}
+
+	for start := fromBlock; start <= toBlock; start += batchSize {
+		end := start + batchSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+
+		canonical := map[uint64]common.Hash{}
+		for _, token := range w.tokens {
+			filterer, err := erc20.NewErc20Filterer(token, client)
+			if err != nil {
+				return err
+			}
+
+			iter, err := filterer.FilterTransfer(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil, w.addresses)
+			if err != nil {
+				return err
+			}
+
+			for iter.Next() {
+				event := iter.Event
+				if event.Raw.Removed {
+					continue
+				}
+
+				ok, err := isCanonical(ctx, client, canonical, event.Raw)
+				if err != nil {
+					iter.Close()
+					return err
+				}
+				if !ok {
+					continue
+				}
+
+				err = handler(&Deposit{
+					Blockchain:      w.blockchain,
+					TxHash:          event.Raw.TxHash.Hex(),
+					LogIndex:        event.Raw.Index,
+					BlockNumber:     event.Raw.BlockNumber,
+					BlockHash:       event.Raw.BlockHash.Hex(),
+					From:            event.From.Hex(),
+					To:              event.To.Hex(),
+					ContractAddress: token.Hex(),
+					Amount:          tokenValue(event.Value, decimals[token]),
+					RawAmount:       event.Value,
+					Confirmations:   head - event.Raw.BlockNumber + 1,
+				})
+				if err != nil {
+					iter.Close()
+					return err
+				}
+			}
+			if err := iter.Error(); err != nil {
+				iter.Close()
+				return err
+			}
+			iter.Close()
+		}
+
+		atomic.StoreUint64(&w.lastBlock, end)
+	}
+	return nil
+}
+
+func (w *ERC20DepositWatcher) tokenDecimals(ctx context.Context, client *ethclient.Client) (map[common.Address]uint8, error) {
+	if len(w.tokens) == 0 || len(w.addresses) == 0 {
+		return nil, errors.New("no token contracts or addresses to watch")
+	}
+
+	decimals := map[common.Address]uint8{}
+	for _, token := range w.tokens {
+		caller, err := erc20.NewErc20Caller(token, client)
+		if err != nil {
+			return nil, err
+		}
+		decimals[token], err = caller.Decimals(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decimals, nil
+}
+
+// confirmedHead is the newest block with at least confirmations
+// confirmations at head. A block confirms itself, so 0 counts as 1 and the
+// result never passes head.
+func confirmedHead(head, confirmations uint64) (uint64, bool) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	if head+1 < confirmations {
+		return 0, false
+	}
+	return head + 1 - confirmations, true
+}
+
+// isCanonical reports whether the log's block is still part of the chain.
+func isCanonical(ctx context.Context, client *ethclient.Client, canonical map[uint64]common.Hash, log types.Log) (bool, error) {
+	hash, ok := canonical[log.BlockNumber]
+	if !ok {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+		if err != nil {
+			return false, err
+		}
+		hash = header.Hash()
+		canonical[log.BlockNumber] = hash
+	}
+	return hash == log.BlockHash, nil
+}
+
+// followHeads calls onHead with the current head and then for every new
+// head until ctx is done or onHead fails.
+func followHeads(ctx context.Context, client *ethclient.Client, gateway string, pollInterval time.Duration, onHead func(head uint64) error) error {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if err := onHead(head); err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(gateway, "ws") {
+		headers := make(chan *types.Header)
+		sub, err := client.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-sub.Err():
+				return err
+			case header := <-headers:
+				if err := onHead(header.Number.Uint64()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if pollInterval <= 0 {
+		pollInterval = defaultDepositPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				return err
+			}
+			if err := onHead(head); err != nil {
+				return err
+			}
+		}
+	}
+}
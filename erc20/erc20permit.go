@@ -0,0 +1,295 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package erc20
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// Erc20PermitMetaData contains all meta data concerning the Erc20Permit contract.
+var Erc20PermitMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"name\":\"DOMAIN_SEPARATOR\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"nonces\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"name\":\"permit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"version\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// Erc20PermitABI is the input ABI used to generate the binding from.
+// Deprecated: Use Erc20PermitMetaData.ABI instead.
+var Erc20PermitABI = Erc20PermitMetaData.ABI
+
+// Erc20Permit is an auto generated Go binding around an Ethereum contract.
+type Erc20Permit struct {
+	Erc20PermitCaller     // Read-only binding to the contract
+	Erc20PermitTransactor // Write-only binding to the contract
+	Erc20PermitFilterer   // Log filterer for contract events
+}
+
+// Erc20PermitCaller is an auto generated read-only Go binding around an Ethereum contract.
+type Erc20PermitCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// Erc20PermitTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type Erc20PermitTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// Erc20PermitFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type Erc20PermitFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// Erc20PermitSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type Erc20PermitSession struct {
+	Contract     *Erc20Permit      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// Erc20PermitCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type Erc20PermitCallerSession struct {
+	Contract *Erc20PermitCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// Erc20PermitTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type Erc20PermitTransactorSession struct {
+	Contract     *Erc20PermitTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// Erc20PermitRaw is an auto generated low-level Go binding around an Ethereum contract.
+type Erc20PermitRaw struct {
+	Contract *Erc20Permit // Generic contract binding to access the raw methods on
+}
+
+// Erc20PermitCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type Erc20PermitCallerRaw struct {
+	Contract *Erc20PermitCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// Erc20PermitTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type Erc20PermitTransactorRaw struct {
+	Contract *Erc20PermitTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewErc20Permit creates a new instance of Erc20Permit, bound to a specific deployed contract.
+func NewErc20Permit(address common.Address, backend bind.ContractBackend) (*Erc20Permit, error) {
+	contract, err := bindErc20Permit(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Erc20Permit{Erc20PermitCaller: Erc20PermitCaller{contract: contract}, Erc20PermitTransactor: Erc20PermitTransactor{contract: contract}, Erc20PermitFilterer: Erc20PermitFilterer{contract: contract}}, nil
+}
+
+// NewErc20PermitCaller creates a new read-only instance of Erc20Permit, bound to a specific deployed contract.
+func NewErc20PermitCaller(address common.Address, caller bind.ContractCaller) (*Erc20PermitCaller, error) {
+	contract, err := bindErc20Permit(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Erc20PermitCaller{contract: contract}, nil
+}
+
+// NewErc20PermitTransactor creates a new write-only instance of Erc20Permit, bound to a specific deployed contract.
+func NewErc20PermitTransactor(address common.Address, transactor bind.ContractTransactor) (*Erc20PermitTransactor, error) {
+	contract, err := bindErc20Permit(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Erc20PermitTransactor{contract: contract}, nil
+}
+
+// NewErc20PermitFilterer creates a new log filterer instance of Erc20Permit, bound to a specific deployed contract.
+func NewErc20PermitFilterer(address common.Address, filterer bind.ContractFilterer) (*Erc20PermitFilterer, error) {
+	contract, err := bindErc20Permit(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &Erc20PermitFilterer{contract: contract}, nil
+}
+
+// bindErc20Permit binds a generic wrapper to an already deployed contract.
+func bindErc20Permit(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := Erc20PermitMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Erc20Permit *Erc20PermitRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Erc20Permit.Contract.Erc20PermitCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Erc20Permit *Erc20PermitRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.Erc20PermitTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Erc20Permit *Erc20PermitRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.Erc20PermitTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Erc20Permit *Erc20PermitCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Erc20Permit.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Erc20Permit *Erc20PermitTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Erc20Permit *Erc20PermitTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.contract.Transact(opts, method, params...)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Erc20Permit *Erc20PermitCaller) DOMAINSEPARATOR(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _Erc20Permit.contract.Call(opts, &out, "DOMAIN_SEPARATOR")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Erc20Permit *Erc20PermitSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _Erc20Permit.Contract.DOMAINSEPARATOR(&_Erc20Permit.CallOpts)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Erc20Permit *Erc20PermitCallerSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _Erc20Permit.Contract.DOMAINSEPARATOR(&_Erc20Permit.CallOpts)
+}
+
+// Nonces is a free data retrieval call binding the contract method 0x7ecebe00.
+//
+// Solidity: function nonces(address owner) view returns(uint256)
+func (_Erc20Permit *Erc20PermitCaller) Nonces(opts *bind.CallOpts, owner common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Erc20Permit.contract.Call(opts, &out, "nonces", owner)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Nonces is a free data retrieval call binding the contract method 0x7ecebe00.
+//
+// Solidity: function nonces(address owner) view returns(uint256)
+func (_Erc20Permit *Erc20PermitSession) Nonces(owner common.Address) (*big.Int, error) {
+	return _Erc20Permit.Contract.Nonces(&_Erc20Permit.CallOpts, owner)
+}
+
+// Nonces is a free data retrieval call binding the contract method 0x7ecebe00.
+//
+// Solidity: function nonces(address owner) view returns(uint256)
+func (_Erc20Permit *Erc20PermitCallerSession) Nonces(owner common.Address) (*big.Int, error) {
+	return _Erc20Permit.Contract.Nonces(&_Erc20Permit.CallOpts, owner)
+}
+
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
+// Solidity: function version() view returns(string)
+func (_Erc20Permit *Erc20PermitCaller) Version(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _Erc20Permit.contract.Call(opts, &out, "version")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
+// Solidity: function version() view returns(string)
+func (_Erc20Permit *Erc20PermitSession) Version() (string, error) {
+	return _Erc20Permit.Contract.Version(&_Erc20Permit.CallOpts)
+}
+
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
+// Solidity: function version() view returns(string)
+func (_Erc20Permit *Erc20PermitCallerSession) Version() (string, error) {
+	return _Erc20Permit.Contract.Version(&_Erc20Permit.CallOpts)
+}
+
+// Permit is a paid mutator transaction binding the contract method 0xd505accf.
+//
+// Solidity: function permit(address owner, address spender, uint256 value, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Erc20Permit *Erc20PermitTransactor) Permit(opts *bind.TransactOpts, owner common.Address, spender common.Address, value *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Erc20Permit.contract.Transact(opts, "permit", owner, spender, value, deadline, v, r, s)
+}
+
+// Permit is a paid mutator transaction binding the contract method 0xd505accf.
+//
+// Solidity: function permit(address owner, address spender, uint256 value, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Erc20Permit *Erc20PermitSession) Permit(owner common.Address, spender common.Address, value *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.Permit(&_Erc20Permit.TransactOpts, owner, spender, value, deadline, v, r, s)
+}
+
+// Permit is a paid mutator transaction binding the contract method 0xd505accf.
+//
+// Solidity: function permit(address owner, address spender, uint256 value, uint256 deadline, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Erc20Permit *Erc20PermitTransactorSession) Permit(owner common.Address, spender common.Address, value *big.Int, deadline *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Erc20Permit.Contract.Permit(&_Erc20Permit.TransactOpts, owner, spender, value, deadline, v, r, s)
+}
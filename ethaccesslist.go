@@ -0,0 +1,43 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// optimizeAccessList asks the node for msg's EIP-2930 access list and returns
+// it with the gas it saves. Lists that save nothing, which is common when the
+// storage touched is cheap to access anyway, are dropped.
+func optimizeAccessList(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	msg.AccessList = nil
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list, _, vmErr, err := gethclient.New(client.Client()).CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if vmErr != "" {
+		return nil, 0, errors.New("eth_createAccessList: " + vmErr)
+	}
+	if list == nil || len(*list) == 0 {
+		return nil, 0, nil
+	}
+
+	msg.AccessList = *list
+	gasWithList, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if gasWithList >= gas {
+		return nil, 0, nil
+	}
+	return *list, gas - gasWithList, nil
+}
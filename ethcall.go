@@ -0,0 +1,34 @@
+package gosendcrypto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ethCallData returns the calldata set with SetCallData or SetContractCall,
+// or an empty slice for plain transfers.
+func ethCallData(cfg *CryptoSender) ([]byte, error) {
+	if cfg.callData != "" && cfg.callMethod != "" {
+		return nil, errors.New("set either call data or a contract call, not both")
+	}
+
+	if cfg.callData != "" {
+		data := cfg.callData
+		if !strings.HasPrefix(data, "0x") && !strings.HasPrefix(data, "0X") {
+			data = "0x" + data
+		}
+		return hexutil.Decode(data)
+	}
+
+	if cfg.callMethod != "" {
+		contractABI, err := abi.JSON(strings.NewReader(cfg.callABI))
+		if err != nil {
+			return nil, err
+		}
+		return contractABI.Pack(cfg.callMethod, cfg.callArgs...)
+	}
+	return []byte{}, nil
+}
@@ -0,0 +1,110 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/payourse/gosendcrypto/ens"
+)
+
+// the ENS registry has the same address on mainnet and the testnets
+var ensRegistryAddr = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+func isENSName(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".eth")
+}
+
+// namehash implements EIP-137. Names are only lower-cased, full ENSIP-15
+// normalisation is left to the caller.
+func namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		copy(node[:], crypto.Keccak256(node[:], labelHash))
+	}
+	return node
+}
+
+// resolveENS returns the address name points to. With verifyReverse the
+// address must also name itself as name through its reverse record.
+func resolveENS(ctx context.Context, client *ethclient.Client, name string, verifyReverse bool) (common.Address, error) {
+	registry, err := ens.NewRegistryCaller(ensRegistryAddr, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	node := namehash(name)
+	resolverAddr, err := registry.Resolver(callOpts, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if resolverAddr == (common.Address{}) {
+		return common.Address{}, errors.New("no ENS resolver set for " + name)
+	}
+
+	resolver, err := ens.NewResolverCaller(resolverAddr, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	addr, err := resolver.Addr(callOpts, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, errors.New("ENS name " + name + " has no address")
+	}
+
+	if !verifyReverse {
+		return addr, nil
+	}
+
+	reverseNode := namehash(strings.ToLower(addr.Hex()[2:]) + ".addr.reverse")
+	reverseResolverAddr, err := registry.Resolver(callOpts, reverseNode)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if reverseResolverAddr == (common.Address{}) {
+		return common.Address{}, errors.New("no reverse record for " + addr.Hex())
+	}
+
+	reverseResolver, err := ens.NewResolverCaller(reverseResolverAddr, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	reverseName, err := reverseResolver.Name(callOpts, reverseNode)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if !strings.EqualFold(reverseName, name) {
+		return common.Address{}, errors.New("reverse record of " + addr.Hex() + " does not match " + name)
+	}
+	return addr, nil
+}
+
+// ethDestination turns a hex address or, on Ethereum, an ENS name into an
+// address.
+func ethDestination(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, to string) (common.Address, error) {
+	if !isENSName(to) {
+		if !common.IsHexAddress(to) {
+			return common.Address{}, errors.New("invalid address found: " + to)
+		}
+		return common.HexToAddress(to), nil
+	}
+	if cfg.blockchain != Blockchain.Ethereum {
+		return common.Address{}, errors.New("ENS names are only supported on ethereum")
+	}
+	return resolveENS(ctx, client, to, cfg.verifyReverseENS)
+}
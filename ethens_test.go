@@ -0,0 +1,52 @@
+package gosendcrypto
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNamehash(t *testing.T) {
+	// EIP-137 vectors
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{"Foo.ETH", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+	for _, tt := range tests {
+		got := namehash(tt.name)
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("namehash(%q) = %x, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsENSName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"vitalik.eth": true,
+		"Pay.ETH":     true,
+		"0x9858EfFD232B4033E47d90003D41EC34EcaEda94": false,
+		"eth": false,
+	} {
+		if got := isENSName(name); got != want {
+			t.Errorf("isENSName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEthDestinationRejectsInvalidAddress(t *testing.T) {
+	cfg := &CryptoSender{blockchain: Blockchain.Ethereum}
+	for _, to := range []string{"", "0x1234", "not-an-address", "0x9858EfFD232B4033E47d90003D41EC34EcaEda9z"} {
+		if _, err := ethDestination(context.Background(), cfg, nil, to); err == nil {
+			t.Errorf("%q: expected an error", to)
+		}
+	}
+	addr, err := ethDestination(context.Background(), cfg, nil, testAccount.Hex())
+	if err != nil || addr != testAccount {
+		t.Errorf("got %s %v, want %s", addr.Hex(), err, testAccount.Hex())
+	}
+}
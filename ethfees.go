@@ -0,0 +1,221 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Fees holds EIP-1559 fee parameters. BaseFee is the base fee the strategy
+// priced against, nil when it is unknown or the chain has none.
+type Fees struct {
+	TipCap  *big.Int
+	FeeCap  *big.Int
+	BaseFee *big.Int
+}
+
+// GasPrice is the price legacy and access list transactions pay. Unlike a
+// fee cap it is charged in full, so it is the base fee plus the tip, bounded
+// by the fee cap. Without a known base fee the fee cap is used as is.
+func (f *Fees) GasPrice() *big.Int {
+	if f.BaseFee == nil {
+		return f.FeeCap
+	}
+	price := new(big.Int).Add(f.BaseFee, f.TipCap)
+	if price.Cmp(f.FeeCap) > 0 {
+		return f.FeeCap
+	}
+	return price
+}
+
+// FeeStrategy decides the EIP-1559 tip and fee cap for the next transaction.
+// The same strategy is used for native and token transfers.
+type FeeStrategy interface {
+	Fees(ctx context.Context, client *ethclient.Client) (*Fees, error)
+}
+
+// NodeFeeStrategy uses the node's suggested tip with a fee cap of twice the
+// latest base fee plus the tip, which stays valid through several full blocks.
+// On chains without a base fee both are set to the suggested gas price.
+type NodeFeeStrategy struct{}
+
+func (NodeFeeStrategy) Fees(ctx context.Context, client *ethclient.Client) (*Fees, error) {
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Fees{
+			TipCap: gasPrice,
+			FeeCap: new(big.Int).Set(gasPrice),
+		}, nil
+	}
+
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fees{
+		TipCap:  tip,
+		FeeCap:  new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip),
+		BaseFee: head.BaseFee,
+	}, nil
+}
+
+// FeeHistoryStrategy takes the median of the given reward percentile over the
+// last Blocks blocks from eth_feeHistory as the tip. Blocks defaults to 20 and
+// Percentile to 50 when zero.
+type FeeHistoryStrategy struct {
+	Blocks     uint64
+	Percentile float64
+}
+
+func (s FeeHistoryStrategy) Fees(ctx context.Context, client *ethclient.Client) (*Fees, error) {
+	blocks := s.Blocks
+	if blocks == 0 {
+		blocks = 20
+	}
+	percentile := s.Percentile
+	if percentile == 0 {
+		percentile = 50
+	}
+	if percentile < 0 || percentile > 100 {
+		return nil, errors.New("fee history percentile must be between 0 and 100")
+	}
+
+	history, err := client.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, err
+	}
+	if len(history.BaseFee) == 0 || len(history.Reward) == 0 {
+		return nil, errors.New("empty fee history")
+	}
+
+	rewards := []*big.Int{}
+	for _, reward := range history.Reward {
+		if len(reward) > 0 {
+			rewards = append(rewards, reward[0])
+		}
+	}
+	if len(rewards) == 0 {
+		return nil, errors.New("empty fee history")
+	}
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+	tip := rewards[len(rewards)/2]
+
+	// the last base fee is the one of the upcoming block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	return &Fees{
+		TipCap:  tip,
+		FeeCap:  new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip),
+		BaseFee: baseFee,
+	}, nil
+}
+
+type FixedFeeStrategy struct {
+	TipGwei    float64
+	MaxFeeGwei float64
+}
+
+func (s FixedFeeStrategy) Fees(ctx context.Context, client *ethclient.Client) (*Fees, error) {
+	if s.MaxFeeGwei < s.TipGwei {
+		return nil, errors.New("max fee should not be less than tip")
+	}
+	return &Fees{
+		TipCap: gweiToWei(s.TipGwei),
+		FeeCap: gweiToWei(s.MaxFeeGwei),
+	}, nil
+}
+
+// MaxFeeCeiling caps the fee cap (and tip) of another strategy so a fee spike
+// never makes a transaction pay more than MaxFeeGwei per gas.
+type MaxFeeCeiling struct {
+	Strategy   FeeStrategy
+	MaxFeeGwei float64
+}
+
+func (s MaxFeeCeiling) Fees(ctx context.Context, client *ethclient.Client) (*Fees, error) {
+	strategy := s.Strategy
+	if strategy == nil {
+		strategy = NodeFeeStrategy{}
+	}
+
+	fees, err := strategy.Fees(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	ceiling := gweiToWei(s.MaxFeeGwei)
+	if fees.FeeCap.Cmp(ceiling) > 0 {
+		fees.FeeCap = ceiling
+	}
+	if fees.TipCap.Cmp(fees.FeeCap) > 0 {
+		fees.TipCap = new(big.Int).Set(fees.FeeCap)
+	}
+	return fees, nil
+}
+
+func (s MaxFeeCeiling) ceiling() *big.Int {
+	return gweiToWei(s.MaxFeeGwei)
+}
+
+// feeCeiling is implemented by strategies whose cap must hold after the chain
+// rules and tip boost are applied.
+type feeCeiling interface {
+	ceiling() *big.Int
+}
+
+// ethFees runs the configured strategy and applies the chain rules and tip
+// boost. The extra tip is added to the fee cap too so the boost is never
+// swallowed by the cap. A MaxFeeCeiling is enforced last.
+func ethFees(ctx context.Context, cfg *CryptoSender, client *ethclient.Client) (*Fees, error) {
+	strategy := cfg.feeStrategy
+	if strategy == nil {
+		strategy = NodeFeeStrategy{}
+	}
+
+	fees, err := strategy.Fees(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	applyChainFeeRules(cfg, fees)
+
+	if cfg.tipBoost > 0 {
+		boost, _ := new(big.Float).Mul(new(big.Float).SetInt(fees.TipCap), big.NewFloat(cfg.tipBoost)).Int(nil)
+		fees.TipCap = new(big.Int).Add(fees.TipCap, boost)
+		fees.FeeCap = new(big.Int).Add(fees.FeeCap, boost)
+	}
+
+	if fees.FeeCap.Cmp(fees.TipCap) < 0 {
+		fees.FeeCap = new(big.Int).Set(fees.TipCap)
+	}
+
+	if capped, ok := strategy.(feeCeiling); ok {
+		ceiling := capped.ceiling()
+		if info, ok := evmChains[cfg.blockchain]; ok && gweiToWei(info.MinTipGwei).Cmp(ceiling) > 0 {
+			return nil, errors.New("max fee ceiling is below the chain's minimum tip")
+		}
+		if fees.FeeCap.Cmp(ceiling) > 0 {
+			fees.FeeCap = ceiling
+		}
+		if fees.TipCap.Cmp(fees.FeeCap) > 0 {
+			fees.TipCap = new(big.Int).Set(fees.FeeCap)
+		}
+	}
+	return fees, nil
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei)).Int(nil)
+	return wei
+}
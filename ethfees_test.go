@@ -0,0 +1,97 @@
+package gosendcrypto
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestFeesGasPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		fees *Fees
+		want int64
+	}{
+		{"base fee plus tip", &Fees{TipCap: big.NewInt(2), FeeCap: big.NewInt(22), BaseFee: big.NewInt(10)}, 12},
+		{"bounded by fee cap", &Fees{TipCap: big.NewInt(2), FeeCap: big.NewInt(11), BaseFee: big.NewInt(10)}, 11},
+		{"no base fee", &Fees{TipCap: big.NewInt(5), FeeCap: big.NewInt(30)}, 30},
+	}
+	for _, tt := range tests {
+		if got := tt.fees.GasPrice(); got.Int64() != tt.want {
+			t.Errorf("%s: got %s, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEthFeesCeilingHoldsAfterChainRules(t *testing.T) {
+	// Polygon raises the 1 gwei tip to its 30 gwei minimum, which must not
+	// push the fee cap over the ceiling
+	cfg := NewCryptoSender(Blockchain.Polygon, Network.Mainnet, "").
+		SetFeeStrategy(MaxFeeCeiling{Strategy: FixedFeeStrategy{TipGwei: 1, MaxFeeGwei: 49}, MaxFeeGwei: 50}).
+		SetTipBoost(0.5)
+
+	fees, err := ethFees(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.FeeCap.Cmp(gweiToWei(50)) != 0 {
+		t.Errorf("got fee cap %s, want the 50 gwei ceiling", fees.FeeCap)
+	}
+	if fees.TipCap.Cmp(fees.FeeCap) > 0 || fees.TipCap.Cmp(gweiToWei(30)) < 0 {
+		t.Errorf("got tip %s", fees.TipCap)
+	}
+
+	cfg.SetFeeStrategy(MaxFeeCeiling{Strategy: FixedFeeStrategy{TipGwei: 1, MaxFeeGwei: 20}, MaxFeeGwei: 20})
+	if _, err := ethFees(context.Background(), cfg, nil); err == nil {
+		t.Error("ceiling below the chain minimum tip accepted")
+	}
+}
+
+func TestCheckChainID(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *CryptoSender
+		chainID int64
+		wantErr bool
+	}{
+		{"ethereum mainnet", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, ""), 1, false},
+		{"ethereum on sepolia as mainnet", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, ""), 11155111, true},
+		{"ethereum on polygon", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, ""), 137, true},
+		{"private network", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, ""), 1337, false},
+		{"pinned chain id", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, "").SetChainID(1337), 1337, false},
+		{"pinned chain id mismatch", NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, "").SetChainID(1337), 1, true},
+		{"bsc testnet", NewCryptoSender(Blockchain.BSC, Network.Testnet, ""), 97, false},
+	}
+	for _, tt := range tests {
+		err := checkChainID(tt.cfg, big.NewInt(tt.chainID))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v", tt.name, err)
+		}
+	}
+}
+
+func TestFeeHistoryStrategyPercentile(t *testing.T) {
+	client, _ := newFakeEthClient(t, 0)
+	tests := []struct {
+		name     string
+		strategy FeeHistoryStrategy
+		wantTip  int64
+	}{
+		{"default", FeeHistoryStrategy{}, 50e9},
+		{"explicit", FeeHistoryStrategy{Blocks: 5, Percentile: 10}, 10e9},
+	}
+	for _, tt := range tests {
+		fees, err := tt.strategy.Fees(context.Background(), client)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if fees.TipCap.Int64() != tt.wantTip {
+			t.Errorf("%s: got tip %s, want %d", tt.name, fees.TipCap, tt.wantTip)
+		}
+	}
+
+	if _, err := (FeeHistoryStrategy{Percentile: 101}).Fees(context.Background(), client); err == nil {
+		t.Error("expected an error for a percentile above 100")
+	}
+}
@@ -0,0 +1,193 @@
+package gosendcrypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/payourse/gosendcrypto/disperse"
+	"github.com/payourse/gosendcrypto/erc20"
+)
+
+// sendMultisend pays every recipient in one transaction through the
+// configured disperse contract. When a token contract is set the disperse
+// contract is first approved for the batch total if its allowance is short.
+func (c *CryptoSender) sendMultisend(ctx context.Context, privateKey string, addrValues []*SendToManyObj) (*SendToManyResult, error) {
+	res := &SendToManyResult{
+		Success: []*sendToManyResObj{},
+		Failed:  []*sendToManyResObj{},
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, privateKey)
+	if err != nil {
+		return res, err
+	}
+
+	// the approval may consume an explicit nonce, so work on a copy
+	cfg := *c
+	fromAddress := crypto.PubkeyToAddress(pk.PublicKey)
+	multisendAddr := common.HexToAddress(c.multisendContract)
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	var token *erc20.Erc20Caller
+	var decimals uint8 = etherDecimals
+	if c.contractAddr != "" {
+		token, err = erc20.NewErc20Caller(common.HexToAddress(c.contractAddr), client)
+		if err != nil {
+			return res, err
+		}
+		decimals, err = token.Decimals(callOpts)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	batch := []*SendToManyObj{}
+	recipients := []common.Address{}
+	values := []*big.Int{}
+	total := big.NewInt(0)
+	for _, addrVal := range addrValues {
+		var value *big.Int
+		if !common.IsHexAddress(addrVal.Address) {
+			err = errors.New("invalid address found: " + addrVal.Address)
+		} else {
+			// decimals stays etherDecimals for native sends
+			value, err = tokenUnits(addrVal.Amount, decimals)
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, &sendToManyResObj{
+				Address: addrVal.Address,
+				Amount:  addrVal.Amount,
+				Err:     err,
+			})
+			if addrVal.TerminateOnFail {
+				return res, err
+			}
+			err = nil
+			continue
+		}
+
+		batch = append(batch, addrVal)
+		recipients = append(recipients, common.HexToAddress(addrVal.Address))
+		values = append(values, value)
+		total = new(big.Int).Add(total, value)
+	}
+
+	if len(batch) == 0 {
+		return res, errors.New("no valid recipients")
+	}
+
+	disperseABI, err := disperse.DisperseMetaData.GetAbi()
+	if err != nil {
+		return res, err
+	}
+
+	var msg ethereum.CallMsg
+	if token != nil {
+		balance, err := token.BalanceOf(callOpts, fromAddress)
+		if err != nil {
+			return res, err
+		}
+		if balance.Cmp(total) == -1 {
+			return res, errors.New("batch total is above the token balance")
+		}
+
+		allowance, err := token.Allowance(callOpts, fromAddress, multisendAddr)
+		if err != nil {
+			return res, err
+		}
+		if allowance.Cmp(total) == -1 {
+			// tokens like USDT revert when one non-zero allowance is
+			// changed to another, so reset it to zero first
+			amounts := []*big.Int{total}
+			if allowance.Sign() > 0 {
+				amounts = []*big.Int{big.NewInt(0), total}
+			}
+			for _, amount := range amounts {
+				approveRes, err := approveMultisend(ctx, &cfg, client, pk, networkID, multisendAddr, amount)
+				if err != nil {
+					return res, err
+				}
+				if cfg.nonce != nil && cfg.nonceManager == nil {
+					nonce := approveRes.Nonce + 1
+					cfg.nonce = &nonce
+				}
+			}
+		}
+
+		data, err := disperseABI.Pack("disperseToken", common.HexToAddress(c.contractAddr), recipients, values)
+		if err != nil {
+			return res, err
+		}
+		msg = ethereumCallMsg(fromAddress, multisendAddr, data)
+	} else {
+		balance, err := client.BalanceAt(ctx, fromAddress, nil)
+		if err != nil {
+			return res, err
+		}
+		if balance.Cmp(total) != 1 {
+			return res, errors.New("batch total should be less than balance")
+		}
+
+		data, err := disperseABI.Pack("disperseEther", recipients, values)
+		if err != nil {
+			return res, err
+		}
+		msg = ethereum.CallMsg{
+			From:  fromAddress,
+			To:    &multisendAddr,
+			Value: total,
+			Data:  data,
+		}
+	}
+
+	result, err := sendEthMsg(ctx, &cfg, client, pk, networkID, msg)
+	if err != nil {
+		for n, addrVal := range batch {
+			failed := &sendToManyResObj{
+				Address: addrVal.Address,
+				Amount:  addrVal.Amount,
+				Err:     err,
+			}
+			if result != nil {
+				failed.TxPosition = n
+				failed.Nonce = result.Nonce
+				failed.TxHash = result.TxHash
+				failed.TxData = result.Data
+			}
+			res.Failed = append(res.Failed, failed)
+		}
+		return res, err
+	}
+
+	for n, addrVal := range batch {
+		res.Success = append(res.Success, &sendToManyResObj{
+			Address:    addrVal.Address,
+			Amount:     addrVal.Amount,
+			TxPosition: n,
+			Nonce:      result.Nonce,
+			TxHash:     result.TxHash,
+			TxData:     result.Data,
+		})
+	}
+	return res, nil
+}
+
+// approveMultisend approves the disperse contract and waits for the approval
+// to be mined, since the batch's gas estimate depends on it.
+func approveMultisend(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, pk *ecdsa.PrivateKey, networkID *big.Int, spender common.Address, amount *big.Int) (*Result, error) {
+	msg, err := erc20Msg(crypto.PubkeyToAddress(pk.PublicKey), common.HexToAddress(cfg.contractAddr), "approve", spender, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	approveCfg := *cfg
+	approveCfg.awaitConfirmation = true
+	return sendEthMsg(ctx, &approveCfg, client, pk, networkID, msg)
+}
@@ -0,0 +1,165 @@
+package gosendcrypto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/payourse/gosendcrypto/erc1155"
+	"github.com/payourse/gosendcrypto/erc721"
+)
+
+// ERC-165 interface ids and the values receiver hooks must return
+var (
+	erc721InterfaceID      = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	erc1155InterfaceID     = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+	erc721ReceivedMagic    = []byte{0x15, 0x0b, 0x7a, 0x02}
+	erc1155ReceivedMagic   = []byte{0xf2, 0x3a, 0x6e, 0x61}
+	errNFTReceiverRejected = errors.New("recipient contract does not accept this token")
+)
+
+// SendNFT transfers tokenID of an ERC721 or ERC1155 contract with
+// safeTransferFrom. amount must be 1 for ERC721. Recipients that are
+// contracts have their receiver hook called first, so tokens are not sent to
+// contracts that would reject them.
+func (c *CryptoSender) SendNFT(ctx context.Context, privateKey, to, contract string, tokenID, amount *big.Int) (*Result, error) {
+	if !isEVM(c.blockchain) {
+		return nil, errors.New("nft transfers are not supported for " + string(c.blockchain))
+	}
+	if !common.IsHexAddress(contract) {
+		return nil, errors.New("invalid contract address: " + contract)
+	}
+	if tokenID == nil || amount == nil || amount.Sign() <= 0 {
+		return nil, errors.New("invalid token id or amount")
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	from := crypto.PubkeyToAddress(pk.PublicKey)
+	toAddress, err := ethDestination(ctx, c, client, to)
+	if err != nil {
+		return nil, err
+	}
+	contractAddr := common.HexToAddress(contract)
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	token, err := erc721.NewErc721Caller(contractAddr, client)
+	if err != nil {
+		return nil, err
+	}
+	// both standards implement ERC-165, so this is safe on either
+	is1155, err := token.SupportsInterface(callOpts, erc1155InterfaceID)
+	if err != nil {
+		return nil, err
+	}
+	is721 := false
+	if !is1155 {
+		is721, err = token.SupportsInterface(callOpts, erc721InterfaceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tokenABI *abi.ABI
+	var data, hook, magic []byte
+	switch {
+	case is721:
+		if amount.Cmp(big.NewInt(1)) != 0 {
+			return nil, errors.New("amount must be 1 for erc721")
+		}
+
+		owner, err := token.OwnerOf(callOpts, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if owner != from {
+			return nil, errors.New("token " + tokenID.String() + " is not owned by " + from.Hex())
+		}
+
+		tokenABI, err = erc721.Erc721MetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		data, err = tokenABI.Pack("safeTransferFrom", from, toAddress, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		hook, err = tokenABI.Pack("onERC721Received", from, from, tokenID, []byte{})
+		if err != nil {
+			return nil, err
+		}
+		magic = erc721ReceivedMagic
+	case is1155:
+		multiToken, err := erc1155.NewErc1155Caller(contractAddr, client)
+		if err != nil {
+			return nil, err
+		}
+
+		balance, err := multiToken.BalanceOf(callOpts, from, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Cmp(amount) < 0 {
+			return nil, errors.New("amount should be less than balance")
+		}
+
+		tokenABI, err = erc1155.Erc1155MetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		data, err = tokenABI.Pack("safeTransferFrom", from, toAddress, tokenID, amount, []byte{})
+		if err != nil {
+			return nil, err
+		}
+		hook, err = tokenABI.Pack("onERC1155Received", from, from, tokenID, amount, []byte{})
+		if err != nil {
+			return nil, err
+		}
+		magic = erc1155ReceivedMagic
+	default:
+		return nil, errors.New(contract + " is neither an erc721 nor an erc1155 contract")
+	}
+
+	err = checkNFTReceiver(ctx, client, contractAddr, toAddress, hook, magic)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := sendEthMsg(ctx, c, client, pk, networkID, ethereumCallMsg(from, contractAddr, data))
+	if result != nil && isENSName(to) {
+		result.ResolvedAddress = toAddress.Hex()
+	}
+	return result, err
+}
+
+// checkNFTReceiver calls the receiver hook on to as the token contract would
+// during safeTransferFrom. Accounts without code always accept.
+func checkNFTReceiver(ctx context.Context, client *ethclient.Client, contractAddr, to common.Address, hook, magic []byte) error {
+	code, err := client.CodeAt(ctx, to, nil)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return nil
+	}
+
+	out, err := client.CallContract(ctx, ethereum.CallMsg{
+		From: contractAddr,
+		To:   &to,
+		Data: hook,
+	}, nil)
+	if err != nil || len(out) < 4 || !bytes.Equal(out[:4], magic) {
+		return errNFTReceiverRejected
+	}
+	return nil
+}
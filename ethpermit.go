@@ -0,0 +1,254 @@
+package gosendcrypto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/payourse/gosendcrypto/erc20"
+)
+
+// Permit is a signed EIP-2612 approval. It can be submitted by anyone, so the
+// owner never needs gas to grant an allowance.
+type Permit struct {
+	Owner    common.Address
+	Spender  common.Address
+	Value    *big.Int
+	Nonce    *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+type PermitTransferResult struct {
+	Permit   *Result
+	Transfer *Result
+}
+
+// SignPermit signs a permit allowing spender to pull amount tokens of the
+// sender's contract from the owner key's account until deadline. No
+// transaction is sent.
+func (c *CryptoSender) SignPermit(ctx context.Context, ownerPrivateKey, spender string, amount float64, deadline time.Time) (*Permit, error) {
+	if err := c.checkTokenSender(); err != nil {
+		return nil, err
+	}
+	if err := checkHexAddresses(spender); err != nil {
+		return nil, err
+	}
+
+	client, pk, _, err := dialEthereum(ctx, c, ownerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	// the EIP-712 domain uses eth_chainId, which can differ from net_version
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := crypto.PubkeyToAddress(pk.PublicKey)
+
+	contractAddr := common.HexToAddress(c.contractAddr)
+	token, err := erc20.NewErc20Caller(contractAddr, client)
+	if err != nil {
+		return nil, err
+	}
+	permitToken, err := erc20.NewErc20PermitCaller(contractAddr, client)
+	if err != nil {
+		return nil, err
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	decimals, err := token.Decimals(callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := tokenUnits(amount, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := permitToken.Nonces(callOpts, owner)
+	if err != nil {
+		return nil, errors.New("token does not support permit: " + err.Error())
+	}
+
+	domain, err := permitDomain(callOpts, token, permitToken, chainID, contractAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	permit := &Permit{
+		Owner:    owner,
+		Spender:  common.HexToAddress(spender),
+		Value:    value,
+		Nonce:    nonce,
+		Deadline: big.NewInt(deadline.Unix()),
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"owner":    permit.Owner.Hex(),
+			"spender":  permit.Spender.Hex(),
+			"value":    permit.Value,
+			"nonce":    permit.Nonce,
+			"deadline": permit.Deadline,
+		},
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := crypto.Sign(hash, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(permit.R[:], sig[:32])
+	copy(permit.S[:], sig[32:64])
+	permit.V = sig[64] + 27
+	return permit, nil
+}
+
+// PermitTransferFrom submits a permit from the relayer key, which must be the
+// permit's spender and pays all gas, then pulls the permitted tokens to to.
+func (c *CryptoSender) PermitTransferFrom(ctx context.Context, relayerPrivateKey string, permit *Permit, to string) (*PermitTransferResult, error) {
+	if err := c.checkTokenSender(); err != nil {
+		return nil, err
+	}
+	if err := checkHexAddresses(to); err != nil {
+		return nil, err
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, relayerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	relayer := crypto.PubkeyToAddress(pk.PublicKey)
+	if relayer != permit.Spender {
+		return nil, errors.New("relayer is not the permit spender")
+	}
+	if permit.Deadline.Cmp(big.NewInt(time.Now().Unix())) < 0 {
+		return nil, errors.New("permit has expired")
+	}
+
+	contractAddr := common.HexToAddress(c.contractAddr)
+	permitABI, err := erc20.Erc20PermitMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := permitABI.Pack("permit", permit.Owner, permit.Spender, permit.Value, permit.Deadline, permit.V, permit.R, permit.S)
+	if err != nil {
+		return nil, err
+	}
+
+	// the transferFrom gas estimate needs the allowance in place, so the
+	// permit has to be mined first
+	permitCfg := *c
+	permitCfg.awaitConfirmation = true
+
+	permitRes, err := sendEthMsg(ctx, &permitCfg, client, pk, networkID, ethereumCallMsg(relayer, contractAddr, data))
+	if err != nil {
+		// a reverted permit still used the relayer's nonce and gas
+		return &PermitTransferResult{Permit: permitRes}, err
+	}
+
+	if permitCfg.nonceManager == nil && permitCfg.nonce != nil {
+		nonce := permitRes.Nonce + 1
+		permitCfg.nonce = &nonce
+	}
+	permitCfg.awaitConfirmation = c.awaitConfirmation
+
+	msg, err := erc20Msg(relayer, contractAddr, "transferFrom", permit.Owner, common.HexToAddress(to), permit.Value)
+	if err != nil {
+		return &PermitTransferResult{Permit: permitRes}, err
+	}
+
+	transferRes, err := sendEthMsg(ctx, &permitCfg, client, pk, networkID, msg)
+	if err != nil {
+		return &PermitTransferResult{Permit: permitRes}, err
+	}
+
+	return &PermitTransferResult{
+		Permit:   permitRes,
+		Transfer: transferRes,
+	}, nil
+}
+
+// permitDomain rebuilds the token's EIP-712 domain and checks it against the
+// on-chain DOMAIN_SEPARATOR. Tokens without version() are tried with the
+// common "1" and "2" (USDC) versions.
+func permitDomain(callOpts *bind.CallOpts, token *erc20.Erc20Caller, permitToken *erc20.Erc20PermitCaller, chainID *big.Int, contractAddr common.Address) (apitypes.TypedDataDomain, error) {
+	name, err := token.Name(callOpts)
+	if err != nil {
+		return apitypes.TypedDataDomain{}, err
+	}
+
+	separator, err := permitToken.DOMAINSEPARATOR(callOpts)
+	if err != nil {
+		return apitypes.TypedDataDomain{}, errors.New("token does not support permit: " + err.Error())
+	}
+
+	versions := []string{"1", "2"}
+	if version, err := permitToken.Version(callOpts); err == nil {
+		versions = []string{version}
+	}
+
+	for _, version := range versions {
+		domain := apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: contractAddr.Hex(),
+		}
+
+		typedData := apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": {
+					{Name: "name", Type: "string"},
+					{Name: "version", Type: "string"},
+					{Name: "chainId", Type: "uint256"},
+					{Name: "verifyingContract", Type: "address"},
+				},
+			},
+			Domain: domain,
+		}
+		hash, err := typedData.HashStruct("EIP712Domain", domain.Map())
+		if err != nil {
+			return apitypes.TypedDataDomain{}, err
+		}
+		if bytes.Equal(hash, separator[:]) {
+			return domain, nil
+		}
+	}
+	return apitypes.TypedDataDomain{}, errors.New("could not match the token's DOMAIN_SEPARATOR")
+}
@@ -0,0 +1,134 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// minReplacementBump is the fee increase nodes require before they accept a
+// transaction replacing another one with the same nonce.
+const minReplacementBump = 0.1
+
+// SpeedUp re-signs a pending transaction with the same nonce, recipient, value
+// and data, with fees raised by bump (0.25 = 25%). The bump is never below the
+// 10% replacement minimum and fees never drop below what the fee strategy
+// currently suggests.
+func (c *CryptoSender) SpeedUp(ctx context.Context, privateKey, txHash string, bump float64) (*Result, error) {
+	return c.replaceEthTx(ctx, privateKey, txHash, bump, false)
+}
+
+// Cancel replaces a pending transaction with a zero-value transfer to the
+// sender itself at the same nonce.
+func (c *CryptoSender) Cancel(ctx context.Context, privateKey, txHash string, bump float64) (*Result, error) {
+	return c.replaceEthTx(ctx, privateKey, txHash, bump, true)
+}
+
+func (c *CryptoSender) replaceEthTx(ctx context.Context, privateKey, txHash string, bump float64, cancel bool) (*Result, error) {
+	if !isEVM(c.blockchain) {
+		return nil, errors.New("tx replacement not supported for " + string(c.blockchain))
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	fromAddress := crypto.PubkeyToAddress(pk.PublicKey)
+
+	oldTx, isPending, err := client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return nil, err
+	}
+	if !isPending {
+		return nil, errors.New("transaction is no longer pending")
+	}
+
+	signer := types.LatestSignerForChainID(networkID)
+	sender, err := types.Sender(signer, oldTx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != fromAddress {
+		return nil, errors.New("transaction was not sent by this private key")
+	}
+
+	if bump < minReplacementBump {
+		bump = minReplacementBump
+	}
+
+	current, err := ethFees(ctx, c, client)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := &Fees{
+		TipCap: maxBig(bumpWei(oldTx.GasTipCap(), bump), current.TipCap),
+		FeeCap: maxBig(bumpWei(oldTx.GasFeeCap(), bump), current.FeeCap),
+	}
+	if oldTx.Type() != types.DynamicFeeTxType {
+		// legacy envelopes pay their gas price in full, so price against
+		// the current base fee rather than the 1559 fee cap
+		price := maxBig(bumpWei(oldTx.GasPrice(), bump), current.GasPrice())
+		fees = &Fees{TipCap: price, FeeCap: price}
+	}
+	if fees.FeeCap.Cmp(fees.TipCap) < 0 {
+		fees.FeeCap = new(big.Int).Set(fees.TipCap)
+	}
+
+	msg := ethereum.CallMsg{
+		From:       fromAddress,
+		To:         oldTx.To(),
+		Value:      oldTx.Value(),
+		Data:       oldTx.Data(),
+		AccessList: oldTx.AccessList(),
+	}
+	gasLimit := oldTx.Gas()
+	if cancel {
+		msg = ethereum.CallMsg{
+			From:  fromAddress,
+			To:    &fromAddress,
+			Value: big.NewInt(0),
+			Data:  []byte{},
+		}
+		gasLimit = params.TxGas
+	}
+
+	txType := TxType.DynamicFee
+	switch oldTx.Type() {
+	case types.LegacyTxType:
+		txType = TxType.Legacy
+	case types.AccessListTxType:
+		txType = TxType.AccessList
+	}
+
+	tx := newEthTx(txType, networkID, oldTx.Nonce(), gasLimit, fees, msg)
+	tx, err = types.SignTx(tx, signer, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return broadcastEthTx(ctx, c, client, tx, nil)
+}
+
+// bumpWei raises value by the bump fraction, rounding up so the result always
+// clears the replacement threshold.
+func bumpWei(value *big.Int, bump float64) *big.Int {
+	percent := big.NewInt(100 + int64(math.Ceil(bump*100)))
+	bumped := new(big.Int).Mul(value, percent)
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return new(big.Int).Set(b)
+}
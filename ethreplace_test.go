@@ -0,0 +1,36 @@
+package gosendcrypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBumpWei(t *testing.T) {
+	tests := []struct {
+		value int64
+		bump  float64
+		want  int64
+	}{
+		{100, 0.1, 110},
+		{1000000000, 0.125, 1130000000},
+		// rounds up so the 10% replacement minimum is always met
+		{15, 0.1, 17},
+		{1, 0.1, 2},
+		{0, 0.5, 0},
+	}
+	for _, tt := range tests {
+		if got := bumpWei(big.NewInt(tt.value), tt.bump); got.Int64() != tt.want {
+			t.Errorf("bumpWei(%d, %v) = %s, want %d", tt.value, tt.bump, got, tt.want)
+		}
+	}
+}
+
+func TestMaxBig(t *testing.T) {
+	a, b := big.NewInt(5), big.NewInt(7)
+	if got := maxBig(a, b); got.Cmp(b) != 0 || got == b {
+		t.Errorf("got %s, want a copy of %s", got, b)
+	}
+	if got := maxBig(b, a); got != b {
+		t.Errorf("got %s, want %s", got, b)
+	}
+}
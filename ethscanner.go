@@ -0,0 +1,254 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NativeDepositScanner walks blocks looking for native coin transfers into
+// watched addresses. Besides plain transactions it follows internal
+// transfers (contract wallets, exchanges paying out via contracts) through
+// debug_traceBlockByNumber when the gateway exposes it. Like the token
+// watcher it only reports blocks that are Confirmations deep.
+type NativeDepositScanner struct {
+	blockchain    BlockchainEnum
+	gateway       string
+	addresses     map[common.Address]bool
+	confirmations uint64
+	pollInterval  time.Duration
+	traceInternal bool
+	lastBlock     uint64
+	// set once the gateway reports debug_traceBlockByNumber as missing
+	traceUnsupported uint32
+}
+
+// JSON-RPC error code for methods the gateway does not implement
+const methodNotFoundCode = -32601
+
+type callFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []callFrame    `json:"calls"`
+}
+
+func NewNativeDepositScanner(blockchain BlockchainEnum, gatewayURL string, addresses []string) *NativeDepositScanner {
+	s := &NativeDepositScanner{
+		blockchain:    blockchain,
+		gateway:       gatewayURL,
+		addresses:     map[common.Address]bool{},
+		confirmations: defaultDepositConfirmations,
+		pollInterval:  defaultDepositPollInterval,
+		traceInternal: true,
+	}
+	for _, addr := range addresses {
+		s.addresses[common.HexToAddress(addr)] = true
+	}
+	return s
+}
+
+func (s *NativeDepositScanner) SetConfirmations(confirmations uint64) *NativeDepositScanner {
+	s.confirmations = confirmations
+	return s
+}
+func (s *NativeDepositScanner) SetPollInterval(interval time.Duration) *NativeDepositScanner {
+	s.pollInterval = interval
+	return s
+}
+func (s *NativeDepositScanner) SetTraceInternal(trace bool) *NativeDepositScanner {
+	s.traceInternal = trace
+	return s
+}
+
+func (s *NativeDepositScanner) LastBlock() uint64 {
+	return atomic.LoadUint64(&s.lastBlock)
+}
+
+// Backfill reports deposits in [fromBlock, toBlock]. toBlock is lowered to
+// the last confirmed block.
+func (s *NativeDepositScanner) Backfill(ctx context.Context, fromBlock, toBlock uint64, handler func(*Deposit) error) error {
+	client, err := ethclient.DialContext(ctx, s.gateway)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	confirmed, ok := confirmedHead(head, s.confirmations)
+	if !ok {
+		return nil
+	}
+	if toBlock > confirmed {
+		toBlock = confirmed
+	}
+	return s.scan(ctx, client, chainID, fromBlock, toBlock, head, handler)
+}
+
+// Watch backfills from fromBlock and then follows new heads until ctx is
+// cancelled.
+func (s *NativeDepositScanner) Watch(ctx context.Context, fromBlock uint64, handler func(*Deposit) error) error {
+	client, err := ethclient.DialContext(ctx, s.gateway)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+
+	next := fromBlock
+	return followHeads(ctx, client, s.gateway, s.pollInterval, func(head uint64) error {
+		confirmed, ok := confirmedHead(head, s.confirmations)
+		if !ok || confirmed < next {
+			return nil
+		}
+
+		err := s.scan(ctx, client, chainID, next, confirmed, head, handler)
+		if err != nil {
+			return err
+		}
+		next = confirmed + 1
+		return nil
+	})
+}
+
+func (s *NativeDepositScanner) scan(ctx context.Context, client *ethclient.Client, chainID *big.Int, fromBlock, toBlock, head uint64, handler func(*Deposit) error) error {
+	signer := types.LatestSignerForChainID(chainID)
+	decimals := 18
+	if info, ok := evmChains[s.blockchain]; ok {
+		decimals = info.Decimals
+	}
+
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return err
+		}
+
+		deposits := []*Deposit{}
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || !s.addresses[*tx.To()] || tx.Value().Sign() <= 0 {
+				continue
+			}
+
+			// a reverted transaction moves no value
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err != nil {
+				return err
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+
+			from, err := types.Sender(signer, tx)
+			if err != nil {
+				return err
+			}
+
+			deposits = append(deposits, &Deposit{
+				TxHash:    tx.Hash().Hex(),
+				From:      from.Hex(),
+				To:        tx.To().Hex(),
+				RawAmount: tx.Value(),
+			})
+		}
+
+		if s.traceInternal && atomic.LoadUint32(&s.traceUnsupported) == 0 {
+			internal, err := s.internalTransfers(ctx, client, number)
+			var rpcErr rpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+				log.Println("debug_traceBlockByNumber unavailable, internal transfers are not scanned:", err.Error())
+				atomic.StoreUint32(&s.traceUnsupported, 1)
+			} else if err != nil {
+				return err
+			} else {
+				deposits = append(deposits, internal...)
+			}
+		}
+
+		for _, deposit := range deposits {
+			deposit.Blockchain = s.blockchain
+			deposit.BlockNumber = number
+			deposit.BlockHash = block.Hash().Hex()
+			deposit.Confirmations = head - number + 1
+			deposit.Amount = tokenValue(deposit.RawAmount, uint8(decimals))
+
+			err = handler(deposit)
+			if err != nil {
+				return err
+			}
+		}
+
+		atomic.StoreUint64(&s.lastBlock, number)
+	}
+	return nil
+}
+
+// internalTransfers traces every transaction of the block and returns value
+// carrying calls into watched addresses below the top-level call, which is
+// already covered by the transaction itself.
+func (s *NativeDepositScanner) internalTransfers(ctx context.Context, client *ethclient.Client, number uint64) ([]*Deposit, error) {
+	var traces []struct {
+		TxHash common.Hash `json:"txHash"`
+		Result callFrame   `json:"result"`
+	}
+	err := client.Client().CallContext(ctx, &traces, "debug_traceBlockByNumber", hexutil.EncodeUint64(number), map[string]string{"tracer": "callTracer"})
+	if err != nil {
+		return nil, err
+	}
+
+	deposits := []*Deposit{}
+	for _, trace := range traces {
+		if trace.Result.Error != "" {
+			continue
+		}
+
+		index := uint(0)
+		var walk func(frames []callFrame)
+		walk = func(frames []callFrame) {
+			for _, frame := range frames {
+				// a failed frame reverts everything below it
+				if frame.Error != "" {
+					continue
+				}
+				if (frame.Type == "CALL" || frame.Type == "CREATE" || frame.Type == "CREATE2" || frame.Type == "SELFDESTRUCT") &&
+					s.addresses[frame.To] && frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+					index++
+					deposits = append(deposits, &Deposit{
+						TxHash:    trace.TxHash.Hex(),
+						LogIndex:  index,
+						From:      frame.From.Hex(),
+						To:        frame.To.Hex(),
+						RawAmount: frame.Value.ToInt(),
+						Internal:  true,
+					})
+				}
+				walk(frame.Calls)
+			}
+		}
+		walk(trace.Result.Calls)
+	}
+	return deposits, nil
+}
@@ -0,0 +1,199 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/payourse/gosendcrypto/erc20"
+)
+
+// custom errors of common token implementations (OpenZeppelin 5, Pausable),
+// used to give readable simulation failures
+const knownErrorsABI = `[
+{"type":"error","name":"ERC20InsufficientBalance","inputs":[{"name":"sender","type":"address"},{"name":"balance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+{"type":"error","name":"ERC20InsufficientAllowance","inputs":[{"name":"spender","type":"address"},{"name":"allowance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+{"type":"error","name":"ERC20InvalidSender","inputs":[{"name":"sender","type":"address"}]},
+{"type":"error","name":"ERC20InvalidReceiver","inputs":[{"name":"receiver","type":"address"}]},
+{"type":"error","name":"ERC20InvalidApprover","inputs":[{"name":"approver","type":"address"}]},
+{"type":"error","name":"ERC20InvalidSpender","inputs":[{"name":"spender","type":"address"}]},
+{"type":"error","name":"EnforcedPause","inputs":[]}
+]`
+
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// BalanceDelta is the net token movement of an address in a simulated call.
+type BalanceDelta struct {
+	Token   string
+	Address string
+	Delta   *big.Int
+}
+
+type tracedLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type tracedFrame struct {
+	Error string        `json:"error"`
+	Logs  []tracedLog   `json:"logs"`
+	Calls []tracedFrame `json:"calls"`
+}
+
+// simulateEthMsg runs msg with eth_call against the pending block before it
+// is broadcast. Reverts are decoded into readable errors, erc20 calls that
+// return false are rejected, and when the gateway supports debug_traceCall
+// the Transfer logs of the call are turned into balance deltas. A transfer
+// that would deliver less than requested (fee-on-transfer tokens) fails.
+func simulateEthMsg(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) ([]*BalanceDelta, error) {
+	out, err := client.PendingCallContract(ctx, msg)
+	if err != nil {
+		return nil, errors.New("simulation reverted: " + revertReason(err))
+	}
+
+	erc20ABI, err := erc20.Erc20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	var method *abi.Method
+	if len(msg.Data) >= 4 {
+		method, _ = erc20ABI.MethodById(msg.Data[:4])
+	}
+
+	// tokens like USDT return nothing, others return false instead of
+	// reverting
+	if method != nil && len(method.Outputs) == 1 && len(out) > 0 {
+		values, err := method.Outputs.Unpack(out)
+		if err == nil {
+			if ok, isBool := values[0].(bool); isBool && !ok {
+				return nil, errors.New("simulation: " + method.Name + " returned false")
+			}
+		}
+	}
+
+	deltas, err := simulatedDeltas(ctx, client, msg)
+	if err != nil {
+		// debug_traceCall is not available on most public gateways
+		return nil, nil
+	}
+
+	if method != nil && (method.Name == "transfer" || method.Name == "transferFrom") {
+		args, err := method.Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			return deltas, err
+		}
+		recipient := args[len(args)-2].(common.Address)
+		amount := args[len(args)-1].(*big.Int)
+
+		received := big.NewInt(0)
+		for _, delta := range deltas {
+			if common.HexToAddress(delta.Token) == *msg.To && common.HexToAddress(delta.Address) == recipient {
+				received = delta.Delta
+			}
+		}
+		if received.Cmp(amount) != 0 {
+			return deltas, fmt.Errorf("simulation: %s would receive %s of %s units", recipient.Hex(), received.String(), amount.String())
+		}
+	}
+	return deltas, nil
+}
+
+func simulatedDeltas(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) ([]*BalanceDelta, error) {
+	arg := map[string]interface{}{
+		"from":  msg.From,
+		"to":    msg.To,
+		"input": hexutil.Bytes(msg.Data),
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+
+	var trace tracedFrame
+	err := client.Client().CallContext(ctx, &trace, "debug_traceCall", arg, "pending", map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"withLog": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := []*BalanceDelta{}
+	index := map[string]*BalanceDelta{}
+	add := func(token, addr common.Address, value *big.Int) {
+		key := token.Hex() + addr.Hex()
+		delta, ok := index[key]
+		if !ok {
+			delta = &BalanceDelta{Token: token.Hex(), Address: addr.Hex(), Delta: big.NewInt(0)}
+			index[key] = delta
+			deltas = append(deltas, delta)
+		}
+		delta.Delta.Add(delta.Delta, value)
+	}
+
+	var walk func(frame tracedFrame)
+	walk = func(frame tracedFrame) {
+		if frame.Error != "" {
+			return
+		}
+		for _, log := range frame.Logs {
+			if len(log.Topics) != 3 || log.Topics[0] != transferTopic || len(log.Data) != 32 {
+				continue
+			}
+			value := new(big.Int).SetBytes(log.Data)
+			add(log.Address, common.BytesToAddress(log.Topics[1].Bytes()), new(big.Int).Neg(value))
+			add(log.Address, common.BytesToAddress(log.Topics[2].Bytes()), value)
+		}
+		for _, call := range frame.Calls {
+			walk(call)
+		}
+	}
+	walk(trace)
+	return deltas, nil
+}
+
+// revertReason extracts Error(string), Panic(uint256) or a known custom
+// error from a failed call, falling back to the raw selector.
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err.Error()
+	}
+
+	dataHex, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	data, decodeErr := hexutil.Decode(dataHex)
+	if decodeErr != nil || len(data) < 4 {
+		return "reverted without a reason"
+	}
+
+	if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+		return reason
+	}
+
+	knownErrors, parseErr := abi.JSON(strings.NewReader(knownErrorsABI))
+	if parseErr == nil {
+		for _, customErr := range knownErrors.Errors {
+			if string(customErr.ID[:4]) != string(data[:4]) {
+				continue
+			}
+			values, unpackErr := customErr.Unpack(data)
+			if unpackErr == nil {
+				return fmt.Sprintf("%s%v", customErr.Name, values)
+			}
+		}
+	}
+	return "custom error " + hexutil.Encode(data[:4])
+}
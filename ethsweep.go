@@ -0,0 +1,86 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// gasPriceOracleAddr is the OP stack predeploy that prices the L1 data fee.
+var gasPriceOracleAddr = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+const gasPriceOracleABI = `[{"inputs":[{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// the L1 base fee can move before the sweep is included, so the quoted L1
+// fee is padded by a quarter
+const l1FeeMarginPercent = 125
+
+// sweepValue is the balance left once the worst case execution fee
+// (gasLimit * fee cap, or gas price for non-1559 txs) and, on OP stack chains, the L1 data fee are paid.
+// Arbitrum folds its L1 cost into the gas estimate so nothing extra is needed
+// there. With EIP-1559 the unused part of the fee cap is refunded, so a little
+// dust can remain in the account.
+func sweepValue(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, txType TxTypeEnum, networkID *big.Int, gasLimit uint64, fees *Fees, msg ethereum.CallMsg) (*big.Int, error) {
+	balance, err := client.BalanceAt(ctx, msg.From, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	feePerGas := fees.FeeCap
+	if txType != TxType.DynamicFee {
+		feePerGas = fees.GasPrice()
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), feePerGas)
+
+	if info, ok := evmChains[cfg.blockchain]; ok && info.OPStack {
+		// price the data of a tx the same size as the final one
+		sized := msg
+		sized.Value = balance
+		unsigned, err := newEthTx(txType, networkID, 0, gasLimit, fees, sized).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		l1Fee, err := l1DataFee(ctx, client, unsigned)
+		if err != nil {
+			return nil, err
+		}
+		l1Fee = new(big.Int).Div(new(big.Int).Mul(l1Fee, big.NewInt(l1FeeMarginPercent)), big.NewInt(100))
+		cost = new(big.Int).Add(cost, l1Fee)
+	}
+
+	value := new(big.Int).Sub(balance, cost)
+	if value.Sign() <= 0 {
+		return nil, errors.New("balance does not cover the network fee")
+	}
+	return value, nil
+}
+
+func l1DataFee(ctx context.Context, client *ethclient.Client, txData []byte) (*big.Int, error) {
+	oracleABI, err := abi.JSON(strings.NewReader(gasPriceOracleABI))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := oracleABI.Pack("getL1Fee", txData)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &gasPriceOracleAddr, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := oracleABI.Unpack("getL1Fee", out)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
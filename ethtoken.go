@@ -0,0 +1,187 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/payourse/gosendcrypto/erc20"
+)
+
+// Approve lets spender pull up to amount tokens of the sender's contract
+// address from the private key's account.
+func (c *CryptoSender) Approve(ctx context.Context, privateKey, spender string, amount float64) (*Result, error) {
+	if err := c.checkTokenSender(); err != nil {
+		return nil, err
+	}
+	if err := checkHexAddresses(spender); err != nil {
+		return nil, err
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	contractAddr := common.HexToAddress(c.contractAddr)
+	caller, err := erc20.NewErc20Caller(contractAddr, client)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := caller.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := tokenUnits(amount, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := erc20Msg(crypto.PubkeyToAddress(pk.PublicKey), contractAddr, "approve", common.HexToAddress(spender), value)
+	if err != nil {
+		return nil, err
+	}
+	return sendEthMsg(ctx, c, client, pk, networkID, msg)
+}
+
+// Allowance returns how many tokens spender may still pull from owner.
+func (c *CryptoSender) Allowance(ctx context.Context, owner, spender string) (float64, error) {
+	if err := c.checkTokenSender(); err != nil {
+		return 0, err
+	}
+	if err := checkHexAddresses(owner, spender); err != nil {
+		return 0, err
+	}
+
+	client, err := ethclient.Dial(c.gateway)
+	if err != nil {
+		return 0, err
+	}
+
+	caller, err := erc20.NewErc20Caller(common.HexToAddress(c.contractAddr), client)
+	if err != nil {
+		return 0, err
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	decimals, err := caller.Decimals(callOpts)
+	if err != nil {
+		return 0, err
+	}
+
+	allowance, err := caller.Allowance(callOpts, common.HexToAddress(owner), common.HexToAddress(spender))
+	if err != nil {
+		return 0, err
+	}
+	return tokenValue(allowance, decimals), nil
+}
+
+// TransferFrom pulls amount tokens from an account that approved the private
+// key's address and sends them to to.
+func (c *CryptoSender) TransferFrom(ctx context.Context, privateKey, from, to string, amount float64) (*Result, error) {
+	if err := c.checkTokenSender(); err != nil {
+		return nil, err
+	}
+	if err := checkHexAddresses(from, to); err != nil {
+		return nil, err
+	}
+
+	client, pk, networkID, err := dialEthereum(ctx, c, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	spender := crypto.PubkeyToAddress(pk.PublicKey)
+	owner := common.HexToAddress(from)
+	contractAddr := common.HexToAddress(c.contractAddr)
+
+	caller, err := erc20.NewErc20Caller(contractAddr, client)
+	if err != nil {
+		return nil, err
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	decimals, err := caller.Decimals(callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := tokenUnits(amount, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := caller.Allowance(callOpts, owner, spender)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Cmp(value) == -1 {
+		return nil, errors.New("amount is above the allowance granted by " + from)
+	}
+
+	balance, err := caller.BalanceOf(callOpts, owner)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(value) == -1 {
+		return nil, errors.New("amount is above the balance of " + from)
+	}
+
+	msg, err := erc20Msg(spender, contractAddr, "transferFrom", owner, common.HexToAddress(to), value)
+	if err != nil {
+		return nil, err
+	}
+	return sendEthMsg(ctx, c, client, pk, networkID, msg)
+}
+
+func (c *CryptoSender) checkTokenSender() error {
+	if !isEVM(c.blockchain) {
+		return errors.New("erc20 is not supported for " + string(c.blockchain))
+	}
+	if c.contractAddr == "" {
+		return errors.New("contract address is not set")
+	}
+	return nil
+}
+
+// checkHexAddresses returns an error for the first address that is not a
+// valid hex address.
+func checkHexAddresses(addrs ...string) error {
+	for _, addr := range addrs {
+		if !common.IsHexAddress(addr) {
+			return errors.New("invalid address found: " + addr)
+		}
+	}
+	return nil
+}
+
+// erc20Msg packs a call to an erc20 method as a zero-value message.
+func erc20Msg(from, contractAddr common.Address, method string, args ...interface{}) (ethereum.CallMsg, error) {
+	erc20ABI, err := erc20.Erc20MetaData.GetAbi()
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+	return ethereumCallMsg(from, contractAddr, data), nil
+}
+
+// ethereumCallMsg is a zero-value contract call.
+func ethereumCallMsg(from, contractAddr common.Address, data []byte) ethereum.CallMsg {
+	return ethereum.CallMsg{
+		From:  from,
+		To:    &contractAddr,
+		Value: big.NewInt(0),
+		Data:  data,
+	}
+}
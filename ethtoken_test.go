@@ -0,0 +1,37 @@
+package gosendcrypto
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenCallsRejectInvalidAddresses(t *testing.T) {
+	ctx := context.Background()
+	c := &CryptoSender{blockchain: Blockchain.Ethereum, contractAddr: testAccount.Hex()}
+	valid := testAccount.Hex()
+
+	if _, err := c.Approve(ctx, "", "0x1234", 1); !isInvalidAddress(err) {
+		t.Error("Approve: expected an error for the spender")
+	}
+	if _, err := c.Allowance(ctx, valid, "bogus"); !isInvalidAddress(err) {
+		t.Error("Allowance: expected an error for the spender")
+	}
+	if _, err := c.TransferFrom(ctx, "", "bogus", valid, 1); !isInvalidAddress(err) {
+		t.Error("TransferFrom: expected an error for from")
+	}
+	if _, err := c.TransferFrom(ctx, "", valid, "", 1); !isInvalidAddress(err) {
+		t.Error("TransferFrom: expected an error for to")
+	}
+	if _, err := c.SignPermit(ctx, "", "bogus", 1, time.Now().Add(time.Hour)); !isInvalidAddress(err) {
+		t.Error("SignPermit: expected an error for the spender")
+	}
+	if _, err := c.PermitTransferFrom(ctx, "", &Permit{}, "0x1234"); !isInvalidAddress(err) {
+		t.Error("PermitTransferFrom: expected an error for to")
+	}
+}
+
+func isInvalidAddress(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "invalid address found")
+}
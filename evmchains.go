@@ -0,0 +1,135 @@
+package gosendcrypto
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// EVMChainInfo describes an EVM network served by the Ethereum sender.
+type EVMChainInfo struct {
+	MainnetChainID  int64
+	TestnetChainIDs []int64
+	Symbol          string
+	Decimals        int
+	// MinTipGwei is the lowest priority fee the chain's validators accept.
+	MinTipGwei float64
+	// DefaultTxType is used instead of base fee detection when the sender's
+	// tx type is TxType.Auto.
+	DefaultTxType TxTypeEnum
+	// OPStack chains charge an extra L1 data fee on top of execution gas.
+	OPStack bool
+}
+
+var evmChains = map[BlockchainEnum]EVMChainInfo{
+	Blockchain.Ethereum: {
+		MainnetChainID:  1,
+		TestnetChainIDs: []int64{11155111, 17000, 5},
+		Symbol:          "ETH",
+		Decimals:        18,
+	},
+	Blockchain.Polygon: {
+		MainnetChainID:  137,
+		TestnetChainIDs: []int64{80002, 80001},
+		Symbol:          "POL",
+		Decimals:        18,
+		MinTipGwei:      30,
+	},
+	Blockchain.BSC: {
+		MainnetChainID:  56,
+		TestnetChainIDs: []int64{97},
+		Symbol:          "BNB",
+		Decimals:        18,
+		DefaultTxType:   TxType.Legacy,
+	},
+	Blockchain.Arbitrum: {
+		MainnetChainID:  42161,
+		TestnetChainIDs: []int64{421614},
+		Symbol:          "ETH",
+		Decimals:        18,
+	},
+	Blockchain.Base: {
+		MainnetChainID:  8453,
+		TestnetChainIDs: []int64{84532},
+		Symbol:          "ETH",
+		Decimals:        18,
+		OPStack:         true,
+	},
+	Blockchain.Avalanche: {
+		MainnetChainID:  43114,
+		TestnetChainIDs: []int64{43113},
+		Symbol:          "AVAX",
+		Decimals:        18,
+	},
+}
+
+func EVMChain(blockchain BlockchainEnum) (EVMChainInfo, bool) {
+	info, ok := evmChains[blockchain]
+	return info, ok
+}
+
+func isEVM(blockchain BlockchainEnum) bool {
+	_, ok := evmChains[blockchain]
+	return ok
+}
+
+// checkChainID makes sure the gateway serves the chain the sender was
+// created for, so a BSC key is never used to sign for Polygon by mistake.
+// Chain IDs none of the known chains use, such as private networks, are
+// accepted unless SetChainID pins the expected ID.
+func checkChainID(cfg *CryptoSender, networkID *big.Int) error {
+	if cfg.chainID != nil {
+		if networkID.Cmp(cfg.chainID) != 0 {
+			return fmt.Errorf("gateway chain id %s does not match configured chain id %s", networkID.String(), cfg.chainID.String())
+		}
+		return nil
+	}
+
+	info, ok := evmChains[cfg.blockchain]
+	if !ok || !isKnownChainID(networkID) {
+		return nil
+	}
+
+	if cfg.network == Network.Testnet {
+		for _, id := range info.TestnetChainIDs {
+			if networkID.Int64() == id {
+				return nil
+			}
+		}
+	} else if networkID.Int64() == info.MainnetChainID {
+		return nil
+	}
+	return fmt.Errorf("gateway chain id %s does not match %s %s", networkID.String(), cfg.blockchain, cfg.network)
+}
+
+func isKnownChainID(networkID *big.Int) bool {
+	if !networkID.IsInt64() {
+		return false
+	}
+	for _, info := range evmChains {
+		if networkID.Int64() == info.MainnetChainID {
+			return true
+		}
+		for _, id := range info.TestnetChainIDs {
+			if networkID.Int64() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyChainFeeRules raises the tip to the chain minimum where validators
+// enforce one.
+func applyChainFeeRules(cfg *CryptoSender, fees *Fees) {
+	info, ok := evmChains[cfg.blockchain]
+	if !ok || info.MinTipGwei <= 0 {
+		return
+	}
+
+	minTip := gweiToWei(info.MinTipGwei)
+	if fees.TipCap.Cmp(minTip) < 0 {
+		diff := new(big.Int).Sub(minTip, fees.TipCap)
+		fees.TipCap = minTip
+		fees.FeeCap = new(big.Int).Add(fees.FeeCap, diff)
+	}
+}
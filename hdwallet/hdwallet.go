@@ -0,0 +1,236 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/craftto/go-tron/pkg/address"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+type Purpose uint32
+
+const (
+	BIP44 Purpose = 44 // legacy P2PKH
+	BIP49 Purpose = 49 // nested segwit P2SH-P2WPKH
+	BIP84 Purpose = 84 // native segwit P2WPKH
+	BIP86 Purpose = 86 // taproot P2TR
+)
+
+const (
+	coinTypeBitcoin        = 0
+	coinTypeBitcoinTestnet = 1
+	coinTypeEthereum       = 60
+	coinTypeTron           = 195
+)
+
+// Key is a derived child key. PrivateKey is in the form CryptoSender expects
+// for the chain: WIF for Bitcoin and hex for Ethereum and Tron.
+type Key struct {
+	Path       string
+	PrivateKey string
+	PublicKey  string
+	Address    string
+}
+
+type Wallet struct {
+	root *hdkeychain.ExtendedKey
+	net  *chaincfg.Params
+}
+
+func NewFromMnemonic(mnemonic, passphrase string, testnet bool) (*Wallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	net := &chaincfg.MainNetParams
+	if testnet {
+		net = &chaincfg.TestNet3Params
+	}
+
+	root, err := hdkeychain.NewMaster(seed, net)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{root: root, net: net}, nil
+}
+
+// NewFromExtendedKey imports an xprv (or tprv). Paths passed to Derive are
+// resolved relative to the imported key, so an account-level xprv should be
+// used with paths such as "m/0/5".
+func NewFromExtendedKey(xprv string) (*Wallet, error) {
+	root, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return nil, err
+	}
+	if !root.IsPrivate() {
+		return nil, errors.New("extended key is not private")
+	}
+
+	net := &chaincfg.MainNetParams
+	if root.IsForNet(&chaincfg.TestNet3Params) {
+		net = &chaincfg.TestNet3Params
+	}
+	return &Wallet{root: root, net: net}, nil
+}
+
+// Derive walks a path such as "m/84'/0'/0'/0/1". Hardened steps may be marked
+// with ' or h.
+func (w *Wallet) Derive(path string) (*hdkeychain.ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := w.root
+	for _, index := range indexes {
+		key, err = key.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// BitcoinKey derives a key on the standard path for the purpose. Only BIP84 is
+// supported because CryptoSender spends from P2WPKH addresses; use Derive and
+// BitcoinAddress for the other address types.
+func (w *Wallet) BitcoinKey(purpose Purpose, account, change, index uint32) (*Key, error) {
+	if purpose != BIP84 {
+		return nil, errors.New("only BIP84 keys can be spent by CryptoSender")
+	}
+
+	coinType := uint32(coinTypeBitcoin)
+	if w.net.Name != chaincfg.MainNetParams.Name {
+		coinType = coinTypeBitcoinTestnet
+	}
+
+	path := fmt.Sprintf("m/%d'/%d'/%d'/%d/%d", purpose, coinType, account, change, index)
+	child, err := w.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+
+	privKey, err := child.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+
+	wif, err := btcutil.NewWIF(privKey, w.net, true)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := BitcoinAddress(privKey.PubKey(), purpose, w.net)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Path:       path,
+		PrivateKey: wif.String(),
+		PublicKey:  hex.EncodeToString(privKey.PubKey().SerializeCompressed()),
+		Address:    addr.EncodeAddress(),
+	}, nil
+}
+
+func (w *Wallet) EthereumKey(account, index uint32) (*Key, error) {
+	path := fmt.Sprintf("m/44'/%d'/%d'/0/%d", coinTypeEthereum, account, index)
+	privKey, err := w.ecPrivKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := privKey.ToECDSA()
+	return &Key{
+		Path:       path,
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(pk)),
+		PublicKey:  hex.EncodeToString(privKey.PubKey().SerializeCompressed()),
+		Address:    crypto.PubkeyToAddress(pk.PublicKey).Hex(),
+	}, nil
+}
+
+func (w *Wallet) TronKey(account, index uint32) (*Key, error) {
+	path := fmt.Sprintf("m/44'/%d'/%d'/0/%d", coinTypeTron, account, index)
+	privKey, err := w.ecPrivKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := privKey.ToECDSA()
+	return &Key{
+		Path:       path,
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(pk)),
+		PublicKey:  hex.EncodeToString(privKey.PubKey().SerializeCompressed()),
+		Address:    address.PubkeyToAddress(pk.PublicKey).String(),
+	}, nil
+}
+
+func (w *Wallet) ecPrivKey(path string) (*btcec.PrivateKey, error) {
+	child, err := w.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return child.ECPrivKey()
+}
+
+// BitcoinAddress returns the address type the purpose prescribes for a
+// public key.
+func BitcoinAddress(pubKey *btcec.PublicKey, purpose Purpose, net *chaincfg.Params) (btcutil.Address, error) {
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	switch purpose {
+	case BIP44:
+		return btcutil.NewAddressPubKeyHash(pubKeyHash, net)
+	case BIP49:
+		witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, net)
+		if err != nil {
+			return nil, err
+		}
+		redeemScript, err := txscript.PayToAddrScript(witnessAddr)
+		if err != nil {
+			return nil, err
+		}
+		return btcutil.NewAddressScriptHash(redeemScript, net)
+	case BIP84:
+		return btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, net)
+	case BIP86:
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		return btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), net)
+	}
+	return nil, errors.New("unsupported purpose")
+}
+
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, errors.New("derivation path must start with m")
+	}
+
+	indexes := []uint32{}
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+		part = strings.TrimRight(part, "'h")
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || index >= hdkeychain.HardenedKeyStart {
+			return nil, errors.New("invalid derivation path: " + path)
+		}
+		if hardened {
+			index += hdkeychain.HardenedKeyStart
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
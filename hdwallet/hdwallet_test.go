@@ -0,0 +1,139 @@
+package hdwallet
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestBitcoinKey(t *testing.T) {
+	tests := []struct {
+		testnet bool
+		path    string
+		address string
+	}{
+		{false, "m/84'/0'/0'/0/0", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{true, "m/84'/1'/0'/0/0", "tb1q6rz28mcfaxtmd6v789l9rrlrusdprr9pqcpvkl"},
+	}
+	for _, tt := range tests {
+		w, err := NewFromMnemonic(testMnemonic, "", tt.testnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := w.BitcoinKey(BIP84, 0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.Path != tt.path || key.Address != tt.address {
+			t.Errorf("got %s %s, want %s %s", key.Path, key.Address, tt.path, tt.address)
+		}
+	}
+
+	w, err := NewFromMnemonic(testMnemonic, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := w.BitcoinKey(BIP84, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "KyZpNDKnfs94vbrwhJneDi77V6jF64PWPF8x5cdJb8ifgg2DUc9d"; key.PrivateKey != want {
+		t.Errorf("got WIF %s, want %s", key.PrivateKey, want)
+	}
+
+	for _, purpose := range []Purpose{BIP44, BIP49, BIP86} {
+		if _, err := w.BitcoinKey(purpose, 0, 0, 0); err == nil {
+			t.Errorf("BIP%d: expected an error", purpose)
+		}
+	}
+}
+
+func TestBitcoinAddress(t *testing.T) {
+	tests := []struct {
+		purpose Purpose
+		path    string
+		address string
+	}{
+		{BIP44, "m/44'/0'/0'/0/0", "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{BIP49, "m/49'/0'/0'/0/0", "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{BIP84, "m/84'/0'/0'/0/0", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{BIP86, "m/86'/0'/0'/0/0", "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+	w, err := NewFromMnemonic(testMnemonic, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		child, err := w.Derive(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := child.ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := BitcoinAddress(pubKey, tt.purpose, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.EncodeAddress() != tt.address {
+			t.Errorf("%s: got %s, want %s", tt.path, addr.EncodeAddress(), tt.address)
+		}
+	}
+}
+
+func TestEthereumAndTronKey(t *testing.T) {
+	w, err := NewFromMnemonic(testMnemonic, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eth, err := w.EthereumKey(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"; eth.Address != want {
+		t.Errorf("got ethereum address %s, want %s", eth.Address, want)
+	}
+	if want := "1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727"; eth.PrivateKey != want {
+		t.Errorf("got ethereum key %s, want %s", eth.PrivateKey, want)
+	}
+
+	tron, err := w.TronKey(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TUEZSdKsoDHQMeZwihtdoBiN46zxhGWYdH"; tron.Address != want {
+		t.Errorf("got tron address %s, want %s", tron.Address, want)
+	}
+}
+
+func TestNewFromMnemonicInvalid(t *testing.T) {
+	if _, err := NewFromMnemonic("abandon abandon abandon", "", false); err == nil {
+		t.Error("invalid mnemonic accepted")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := ParsePath("m/84'/0'/0'/1/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{0x80000054, 0x80000000, 0x80000000, 1, 5}
+	if len(path) != len(want) {
+		t.Fatalf("got %v, want %v", path, want)
+	}
+	for n := range want {
+		if path[n] != want[n] {
+			t.Fatalf("got %v, want %v", path, want)
+		}
+	}
+
+	for _, bad := range []string{"84'/0'", "m/x/0", "m/2147483648"} {
+		if _, err := ParsePath(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+type extendedKeyVersion struct {
+	purpose Purpose
+	net     *chaincfg.Params
+}
+
+// SLIP-0132 public key versions. A plain xpub/tpub is treated as BIP44; set
+// Purpose to BIP86 on the returned WatchOnly for taproot account keys.
+var publicKeyVersions = map[string]extendedKeyVersion{
+	"0488b21e": {BIP44, &chaincfg.MainNetParams},  // xpub
+	"049d7cb2": {BIP49, &chaincfg.MainNetParams},  // ypub
+	"04b24746": {BIP84, &chaincfg.MainNetParams},  // zpub
+	"043587cf": {BIP44, &chaincfg.TestNet3Params}, // tpub
+	"044a5262": {BIP49, &chaincfg.TestNet3Params}, // upub
+	"045f1cf6": {BIP84, &chaincfg.TestNet3Params}, // vpub
+}
+
+// WatchOnly derives receive and change addresses from an account-level
+// extended public key without access to any private key.
+type WatchOnly struct {
+	Purpose Purpose
+	key     *hdkeychain.ExtendedKey
+	net     *chaincfg.Params
+}
+
+func ParseExtendedPublicKey(xpub string) (*WatchOnly, error) {
+	key, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("extended key is private, expected an xpub")
+	}
+
+	version, ok := publicKeyVersions[hex.EncodeToString(key.Version())]
+	if !ok {
+		return nil, errors.New("unknown extended public key version")
+	}
+
+	return &WatchOnly{
+		Purpose: version.purpose,
+		key:     key,
+		net:     version.net,
+	}, nil
+}
+
+func (w *WatchOnly) Net() *chaincfg.Params {
+	return w.net
+}
+
+func (w *WatchOnly) Address(change, index uint32) (string, error) {
+	if change > 1 {
+		return "", errors.New("change must be 0 (receive) or 1 (change)")
+	}
+
+	branch, err := w.key.Derive(change)
+	if err != nil {
+		return "", err
+	}
+	child, err := branch.Derive(index)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := BitcoinAddress(pubKey, w.Purpose, w.net)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+func (w *WatchOnly) Addresses(change, start, count uint32) ([]string, error) {
+	addresses := []string{}
+	for index := start; index < start+count; index++ {
+		addr, err := w.Address(change, index)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
@@ -0,0 +1,42 @@
+package hdwallet
+
+import "testing"
+
+// BIP84 account 0 of the "abandon ... about" mnemonic
+const testZpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+func TestWatchOnlyAddresses(t *testing.T) {
+	w, err := ParseExtendedPublicKey(testZpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Purpose != BIP84 {
+		t.Errorf("got purpose %d, want %d", w.Purpose, BIP84)
+	}
+
+	receive, err := w.Addresses(0, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"}
+	for n := range want {
+		if receive[n] != want[n] {
+			t.Errorf("receive %d: got %s, want %s", n, receive[n], want[n])
+		}
+	}
+
+	change, err := w.Address(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"; change != want {
+		t.Errorf("change: got %s, want %s", change, want)
+	}
+}
+
+func TestParseExtendedPublicKeyRejectsPrivate(t *testing.T) {
+	xprv := "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+	if _, err := ParseExtendedPublicKey(xprv); err == nil {
+		t.Error("private extended key accepted")
+	}
+}
@@ -0,0 +1,29 @@
+package gosendcrypto
+
+import (
+	"encoding/hex"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DecryptKeystore decrypts a Web3 Secret Storage (scrypt or pbkdf2) key and
+// returns the private key as hex, ready to pass to Sendcrypto or SendToMany
+// for Ethereum and Tron.
+func DecryptKeystore(keyJSON []byte, passphrase string) (string, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)), nil
+}
+
+// LoadKeystore reads and decrypts the keystore file at path.
+func LoadKeystore(path, passphrase string) (string, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return DecryptKeystore(keyJSON, passphrase)
+}
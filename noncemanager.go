@@ -0,0 +1,275 @@
+package gosendcrypto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// NonceStore persists the next nonce of each account so a restarted process
+// does not reuse nonces of transactions a lagging gateway has not seen yet.
+// A stored nonce is a lower bound, discarded like any other stale nonce, see
+// NonceManager.Reserve.
+type NonceStore interface {
+	Load(key string) (nonce uint64, ok bool, err error)
+	Save(key string, nonce uint64) error
+}
+
+// NonceManager hands out nonces for EVM accounts. It is safe for concurrent
+// use and is meant to be shared by every CryptoSender signing for the same
+// accounts.
+type NonceManager struct {
+	mu         sync.Mutex
+	accounts   map[string]*accountNonces
+	store      NonceStore
+	staleAfter time.Duration
+}
+
+// defaultNonceStaleAfter is how long the pending nonce may sit below the
+// manager's next nonce before the difference is treated as dropped.
+const defaultNonceStaleAfter = 5 * time.Minute
+
+type accountNonces struct {
+	mu       sync.Mutex
+	loaded   bool
+	next     uint64
+	released []uint64
+	// the highest pending nonce seen and when it last went up
+	pending    uint64
+	progressed time.Time
+	// nonces handed out but neither committed nor released yet
+	reserved map[uint64]bool
+}
+
+// NonceReservation is a nonce handed out by a NonceManager. Commit it once
+// the transaction was broadcast, otherwise Release it so the nonce is reused
+// and no gap is left behind.
+type NonceReservation struct {
+	Nonce   uint64
+	key     string
+	manager *NonceManager
+	done    bool
+}
+
+// NewNonceManager creates a manager; store may be nil to keep state in memory.
+func NewNonceManager(store NonceStore) *NonceManager {
+	return &NonceManager{
+		accounts:   map[string]*accountNonces{},
+		store:      store,
+		staleAfter: defaultNonceStaleAfter,
+	}
+}
+
+// SetStaleAfter sets how long the node's pending nonce may stay behind the
+// manager's next nonce without advancing before the gap is handed out again.
+func (m *NonceManager) SetStaleAfter(staleAfter time.Duration) *NonceManager {
+	m.staleAfter = staleAfter
+	return m
+}
+
+func nonceKey(chainID *big.Int, addr common.Address) string {
+	return chainID.String() + ":" + strings.ToLower(addr.Hex())
+}
+
+func (m *NonceManager) account(key string) *accountNonces {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.accounts[key]
+	if !ok {
+		account = &accountNonces{reserved: map[uint64]bool{}}
+		m.accounts[key] = account
+	}
+	return account
+}
+
+// Reserve returns the lowest nonce that is neither used on chain (including
+// the pending pool) nor reserved by another caller, and never below the
+// manager's own next nonce, which survives restarts through the store.
+//
+// The next nonce is discarded in favour of the node's pending nonce once the
+// pending nonce has been behind it without advancing for the stale period
+// (5 minutes unless SetStaleAfter changes it). That recovers transactions
+// dropped from the mempool and stores left ahead of the chain, while a
+// gateway that briefly lags behind a broadcast never causes a reused nonce.
+func (m *NonceManager) Reserve(ctx context.Context, client *ethclient.Client, chainID *big.Int, addr common.Address) (*NonceReservation, error) {
+	key := nonceKey(chainID, addr)
+	account := m.account(key)
+
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	if !account.loaded && m.store != nil {
+		stored, ok, err := m.store.Load(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			account.next = stored
+		}
+	}
+	account.loaded = true
+
+	pending, err := client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if account.progressed.IsZero() || pending > account.pending {
+		account.pending = pending
+		account.progressed = time.Now()
+	}
+
+	floor := pending
+	for reserved := range account.reserved {
+		if reserved+1 > floor {
+			floor = reserved + 1
+		}
+	}
+	next := account.next
+	if next < floor || time.Since(account.progressed) >= m.staleAfter {
+		next = floor
+	}
+
+	// released nonces below the pending nonce were filled by someone else
+	released := []uint64{}
+	for _, nonce := range account.released {
+		if nonce >= pending && nonce < next {
+			released = append(released, nonce)
+		}
+	}
+
+	var nonce uint64
+	if len(released) > 0 {
+		nonce = released[0]
+		released = released[1:]
+	} else {
+		nonce = next
+		next++
+	}
+
+	// persist before handing the nonce out so a failed save loses nothing
+	if m.store != nil {
+		err := m.store.Save(key, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	account.next = next
+	account.released = released
+	account.reserved[nonce] = true
+
+	return &NonceReservation{
+		Nonce:   nonce,
+		key:     key,
+		manager: m,
+	}, nil
+}
+
+// Commit marks the nonce as used by a broadcast transaction.
+func (r *NonceReservation) Commit() {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+
+	account := r.manager.account(r.key)
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	delete(account.reserved, r.Nonce)
+}
+
+// Release hands the nonce back after a failed broadcast. It is a no-op once
+// the reservation was committed, so it can be deferred.
+func (r *NonceReservation) Release() {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+
+	account := r.manager.account(r.key)
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	delete(account.reserved, r.Nonce)
+	if r.Nonce+1 == account.next {
+		account.next--
+		if r.manager.store != nil {
+			err := r.manager.store.Save(r.key, account.next)
+			if err != nil {
+				log.Println("nonce store save error for", r.key, err.Error())
+			}
+		}
+		return
+	}
+	account.released = append(account.released, r.Nonce)
+	sort.Slice(account.released, func(i, j int) bool { return account.released[i] < account.released[j] })
+}
+
+// FileNonceStore keeps nonces in a JSON file.
+type FileNonceStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNonceStore(path string) *FileNonceStore {
+	return &FileNonceStore{path: path}
+}
+
+func (s *FileNonceStore) Load(key string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonces, err := s.read()
+	if err != nil {
+		return 0, false, err
+	}
+	nonce, ok := nonces[key]
+	return nonce, ok, nil
+}
+
+func (s *FileNonceStore) Save(key string, nonce uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonces, err := s.read()
+	if err != nil {
+		return err
+	}
+	nonces[key] = nonce
+
+	data, err := json.MarshalIndent(nonces, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileNonceStore) read() (map[string]uint64, error) {
+	nonces := map[string]uint64{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nonces, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &nonces)
+	return nonces, err
+}
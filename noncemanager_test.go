@@ -0,0 +1,195 @@
+package gosendcrypto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeEth serves eth_getTransactionCount with a settable pending nonce,
+// and eth_feeHistory with each block's reward set to the requested percentile
+// in gwei.
+type fakeEth struct {
+	mu      sync.Mutex
+	pending uint64
+}
+
+type fakeFeeHistory struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+func (f *fakeEth) FeeHistory(blocks hexutil.Uint64, lastBlock string, percentiles []float64) *fakeFeeHistory {
+	history := &fakeFeeHistory{OldestBlock: (*hexutil.Big)(big.NewInt(1))}
+	for i := uint64(0); i < uint64(blocks); i++ {
+		rewards := []*hexutil.Big{}
+		for _, p := range percentiles {
+			rewards = append(rewards, (*hexutil.Big)(big.NewInt(int64(p)*1e9)))
+		}
+		history.Reward = append(history.Reward, rewards)
+		history.BaseFee = append(history.BaseFee, (*hexutil.Big)(big.NewInt(1e9)))
+		history.GasUsedRatio = append(history.GasUsedRatio, 0.5)
+	}
+	history.BaseFee = append(history.BaseFee, (*hexutil.Big)(big.NewInt(1e9)))
+	return history
+}
+
+func (f *fakeEth) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return hexutil.Uint64(f.pending)
+}
+
+func (f *fakeEth) setPending(nonce uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = nonce
+}
+
+func newFakeEthClient(t *testing.T, pending uint64) (*ethclient.Client, *fakeEth) {
+	eth := &fakeEth{pending: pending}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", eth); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return client, eth
+}
+
+type memNonceStore struct {
+	nonces  map[string]uint64
+	saveErr error
+}
+
+func (s *memNonceStore) Load(key string) (uint64, bool, error) {
+	nonce, ok := s.nonces[key]
+	return nonce, ok, nil
+}
+
+func (s *memNonceStore) Save(key string, nonce uint64) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.nonces[key] = nonce
+	return nil
+}
+
+var (
+	testChainID = big.NewInt(1)
+	testAccount = common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")
+)
+
+func reserveNonce(t *testing.T, m *NonceManager, client *ethclient.Client) *NonceReservation {
+	t.Helper()
+	r, err := m.Reserve(context.Background(), client, testChainID, testAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestNonceManagerReserveRelease(t *testing.T) {
+	client, eth := newFakeEthClient(t, 5)
+	m := NewNonceManager(nil)
+
+	a, b, c := reserveNonce(t, m, client), reserveNonce(t, m, client), reserveNonce(t, m, client)
+	if a.Nonce != 5 || b.Nonce != 6 || c.Nonce != 7 {
+		t.Fatalf("got %d %d %d, want 5 6 7", a.Nonce, b.Nonce, c.Nonce)
+	}
+
+	// a released nonce in the middle is handed out again before new ones
+	b.Release()
+	if d := reserveNonce(t, m, client); d.Nonce != 6 {
+		t.Fatalf("got %d, want the released 6", d.Nonce)
+	}
+
+	// releasing the newest nonce just rewinds
+	c.Release()
+	if e := reserveNonce(t, m, client); e.Nonce != 7 {
+		t.Fatalf("got %d, want 7 after the rewind", e.Nonce)
+	}
+
+	// committed nonces are never handed out again while in the pool
+	a.Commit()
+	a.Release()
+	eth.setPending(6)
+	if f := reserveNonce(t, m, client); f.Nonce != 8 {
+		t.Fatalf("got %d, want 8", f.Nonce)
+	}
+}
+
+func TestNonceManagerResyncsWithChain(t *testing.T) {
+	client, eth := newFakeEthClient(t, 5)
+	m := NewNonceManager(nil)
+
+	r := reserveNonce(t, m, client)
+	r.Commit()
+	// a gateway lagging behind the broadcast does not get 5 reused
+	lagging := reserveNonce(t, m, client)
+	if lagging.Nonce != 6 {
+		t.Fatalf("got %d, want 6 while the gateway catches up", lagging.Nonce)
+	}
+	lagging.Release()
+
+	// once the pending nonce stops advancing the tx counts as dropped
+	m.SetStaleAfter(0)
+	again := reserveNonce(t, m, client)
+	if again.Nonce != 5 {
+		t.Fatalf("got %d, want the dropped 5", again.Nonce)
+	}
+	again.Commit()
+
+	// someone else used the account
+	eth.setPending(9)
+	if next := reserveNonce(t, m, client); next.Nonce != 9 {
+		t.Fatalf("got %d, want 9", next.Nonce)
+	}
+}
+
+func TestNonceManagerStore(t *testing.T) {
+	client, _ := newFakeEthClient(t, 5)
+	key := nonceKey(testChainID, testAccount)
+
+	// a restart keeps the stored nonce over a gateway that has not caught up
+	store := &memNonceStore{nonces: map[string]uint64{key: 10}}
+	m := NewNonceManager(store)
+	if r := reserveNonce(t, m, client); r.Nonce != 10 {
+		t.Fatalf("got %d, want the stored 10", r.Nonce)
+	}
+	if store.nonces[key] != 11 {
+		t.Fatalf("stored %d, want 11", store.nonces[key])
+	}
+
+	// a store left stale ahead of the chain does not leave a gap
+	store = &memNonceStore{nonces: map[string]uint64{key: 10}}
+	m = NewNonceManager(store).SetStaleAfter(0)
+	if r := reserveNonce(t, m, client); r.Nonce != 5 {
+		t.Fatalf("got %d, want 5", r.Nonce)
+	}
+	if store.nonces[key] != 6 {
+		t.Fatalf("stored %d, want 6", store.nonces[key])
+	}
+
+	// a failed save hands nothing out and loses nothing
+	store.saveErr = errors.New("disk full")
+	if _, err := m.Reserve(context.Background(), client, testChainID, testAccount); err == nil {
+		t.Fatal("expected the save error")
+	}
+	store.saveErr = nil
+	if r := reserveNonce(t, m, client); r.Nonce != 6 {
+		t.Fatalf("got %d, want 6", r.Nonce)
+	}
+}
@@ -3,19 +3,30 @@ package gosendcrypto
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"log"
 	"math"
+	"math/big"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/txsort"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/imroc/req/v3"
+	"github.com/payourse/gosendcrypto/hdwallet"
 )
 
+const defaultBitcoinPollInterval = 30 * time.Second
+
+// status errors tolerated in a row while waiting for confirmations
+const maxBitcoinStatusErrors = 5
+
 var networks = map[string]*chaincfg.Params{
 	"testnet": &chaincfg.TestNet3Params,
 	"":        &chaincfg.MainNetParams,
@@ -34,7 +45,7 @@ func sendBitcoin(ctx context.Context, cfg *CryptoSender, privKey, toAddressStr s
 		return sendBitcoinToMany(ctx, cfg, privKey, toAddressStr, amount, addrValues...)
 	}
 
-	addrPubKey, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.PrivKey.PubKey().SerializeCompressed()), chain)
+	addrPubKey, err := hdwallet.BitcoinAddress(wif.PrivKey.PubKey(), hdwallet.BIP84, chain)
 	if err != nil {
 		return nil, err
 	}
@@ -156,10 +167,15 @@ func sendBitcoin(ctx context.Context, cfg *CryptoSender, privKey, toAddressStr s
 		return nil, errors.New("http req failed")
 	}
 
+	lockTime, sequence, err := txLockTime(cfg, uint32(infoResult.Result.BlockchainHeight))
+	if err != nil {
+		return nil, err
+	}
+
 	redeemTx := wire.NewMsgTx(2)
 	outPoint := wire.NewOutPoint(utxoHash, uint32(position))
 	txIn := wire.NewTxIn(outPoint, nil, [][]byte{})
-	txIn.Sequence = txIn.Sequence - 2
+	txIn.Sequence = sequence
 	redeemTx.AddTxIn(txIn)
 
 	redeemTxOut0 := wire.NewTxOut(int64(satValue), destAddrByte)
@@ -167,11 +183,15 @@ func sendBitcoin(ctx context.Context, cfg *CryptoSender, privKey, toAddressStr s
 
 	redeemTx.AddTxOut(redeemTxOut1) // add the change first (index=0)
 	redeemTx.AddTxOut(redeemTxOut0)
-	redeemTx.LockTime = uint32(infoResult.Result.BlockchainHeight)
+	redeemTx.LockTime = lockTime
 
 	size := redeemTx.SerializeSize()
 	redeemTxOut1.Value = int64(balance - 50 - int(satValue) - (size * infoResult.Result.FeePerKb / 1000))
 
+	if err := orderOutputs(redeemTx, cfg.outputOrder); err != nil {
+		return nil, err
+	}
+
 	a := txscript.NewMultiPrevOutFetcher(map[wire.OutPoint]*wire.TxOut{
 		*outPoint: {},
 	})
@@ -213,8 +233,17 @@ func sendBitcoin(ctx context.Context, cfg *CryptoSender, privKey, toAddressStr s
 	}
 
 	res := &Result{
-		TxHash:     broadcastResult.Result,
-		TxPosition: 0,
+		TxHash:         broadcastResult.Result,
+		TxPosition:     outputPosition(redeemTx, redeemTxOut0),
+		ChangePosition: outputPosition(redeemTx, redeemTxOut1),
+	}
+
+	if cfg.awaitConfirmation {
+		// the tx is out, so keep its hash even if waiting fails
+		err := waitBitcoinConfirmations(ctx, cfg, broadcastResult.Result)
+		if err != nil {
+			return res, err
+		}
 	}
 	return res, nil
 }
@@ -228,7 +257,7 @@ func sendBitcoinToMany(ctx context.Context, cfg *CryptoSender, privKey, toAddres
 		return nil, err
 	}
 
-	addrPubKey, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.PrivKey.PubKey().SerializeCompressed()), chain)
+	addrPubKey, err := hdwallet.BitcoinAddress(wif.PrivKey.PubKey(), hdwallet.BIP84, chain)
 	if err != nil {
 		return nil, err
 	}
@@ -360,25 +389,36 @@ func sendBitcoinToMany(ctx context.Context, cfg *CryptoSender, privKey, toAddres
 		return nil, errors.New("http req failed")
 	}
 
+	lockTime, sequence, err := txLockTime(cfg, uint32(infoResult.Result.BlockchainHeight))
+	if err != nil {
+		return nil, err
+	}
+
 	redeemTx := wire.NewMsgTx(2)
 	outPoint := wire.NewOutPoint(utxoHash, uint32(position))
 	txIn := wire.NewTxIn(outPoint, nil, [][]byte{})
-	txIn.Sequence = txIn.Sequence - 2
+	txIn.Sequence = sequence
 	redeemTx.AddTxIn(txIn)
 
 	redeemTxOut1 := wire.NewTxOut(int64(balance), spenderAddrByte)
 	redeemTx.AddTxOut(redeemTxOut1) // add the change first (index=0)
 
+	destTxOuts := []*wire.TxOut{}
 	for n, destAddrByte := range destAddrBytes {
 		redeemTxOut := wire.NewTxOut(int64(destAddrValues[n]), destAddrByte)
 		redeemTx.AddTxOut(redeemTxOut)
+		destTxOuts = append(destTxOuts, redeemTxOut)
 	}
 
-	redeemTx.LockTime = uint32(infoResult.Result.BlockchainHeight)
+	redeemTx.LockTime = lockTime
 
 	size := redeemTx.SerializeSize()
 	redeemTxOut1.Value = int64(balance - 50 - totalSatValue - (size * infoResult.Result.FeePerKb / 1000))
 
+	if err := orderOutputs(redeemTx, cfg.outputOrder); err != nil {
+		return nil, err
+	}
+
 	a := txscript.NewMultiPrevOutFetcher(map[wire.OutPoint]*wire.TxOut{
 		*outPoint: {},
 	})
@@ -419,9 +459,228 @@ func sendBitcoinToMany(ctx context.Context, cfg *CryptoSender, privKey, toAddres
 		return nil, errors.New("http req failed")
 	}
 
+	outputPositions := []int{}
+	for _, destTxOut := range destTxOuts {
+		outputPositions = append(outputPositions, outputPosition(redeemTx, destTxOut))
+	}
+
 	res := &Result{
-		TxHash:     broadcastResult.Result,
-		TxPosition: 0,
+		TxHash:          broadcastResult.Result,
+		TxPosition:      outputPositions[0],
+		OutputPositions: outputPositions,
+		ChangePosition:  outputPosition(redeemTx, redeemTxOut1),
+	}
+
+	if cfg.awaitConfirmation {
+		// the tx is out, so keep its hash even if waiting fails
+		err := waitBitcoinConfirmations(ctx, cfg, broadcastResult.Result)
+		if err != nil {
+			return res, err
+		}
 	}
 	return res, nil
 }
+
+func bitcoinTxStatus(ctx context.Context, cfg *CryptoSender, txHash string) (*TxStatus, error) {
+	var info struct {
+		BlockchainHeight int `json:"blockchain_height"`
+	}
+	err := electrumCall(ctx, cfg.gateway, "getinfo", &info)
+	if err != nil {
+		return nil, err
+	}
+
+	var verbose struct {
+		Confirmations int    `json:"confirmations"`
+		BlockHash     string `json:"blockhash"`
+	}
+	err = electrumCall(ctx, cfg.gateway, "blockchain.transaction.get", &verbose, txHash, true)
+	if err != nil {
+		// Electrum daemons without the server passthrough only know
+		// about wallet transactions
+		var walletStatus struct {
+			Confirmations int `json:"confirmations"`
+		}
+		statusErr := electrumCall(ctx, cfg.gateway, "get_tx_status", &walletStatus, txHash)
+		if statusErr != nil {
+			return nil, err
+		}
+		verbose.Confirmations = walletStatus.Confirmations
+	}
+
+	status := &TxStatus{
+		TxHash:        txHash,
+		Confirmations: verbose.Confirmations,
+		BlockHash:     verbose.BlockHash,
+		InMempool:     verbose.Confirmations <= 0,
+	}
+	if verbose.Confirmations > 0 {
+		status.BlockHeight = info.BlockchainHeight - verbose.Confirmations + 1
+	}
+	return status, nil
+}
+
+func waitBitcoinConfirmations(ctx context.Context, cfg *CryptoSender, txHash string) error {
+	confirmations := cfg.confirmations
+	if confirmations < 1 {
+		confirmations = 1
+	}
+	interval := cfg.pollInterval
+	if interval <= 0 {
+		interval = defaultBitcoinPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		status, err := bitcoinTxStatus(ctx, cfg, txHash)
+		if err != nil {
+			failures++
+			if failures >= maxBitcoinStatusErrors {
+				return err
+			}
+			log.Println("bitcoin tx status error for", txHash, err.Error())
+		} else if status.Confirmations >= confirmations {
+			return nil
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// electrumCall sends a single JSON-RPC request to the Electrum gateway and
+// decodes its result into result.
+func electrumCall(ctx context.Context, gateway, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body := struct {
+		Jsonrpc string        `json:"jsonrpc"`
+		Method  string        `json:"method"`
+		ID      string        `json:"id"`
+		Params  []interface{} `json:"params"`
+	}{
+		Jsonrpc: "2.0",
+		Method:  method,
+		ID:      "1101",
+		Params:  params,
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	resp, err := req.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json").
+		SetResult(&response).
+		SetBody(&body).
+		Post(gateway)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.IsError() {
+		return errors.New("http req failed")
+	}
+
+	if response.Error != nil {
+		return errors.New(method + " failed: " + response.Error.Message)
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
+
+// txLockTime returns the nLockTime and input sequence for a transaction built
+// on top of the given chain height. Inputs signal BIP-125 replaceability
+// unless disabled; otherwise the sequence is one below final so the locktime
+// is still enforced, and only LockTime.None makes it final.
+func txLockTime(cfg *CryptoSender, height uint32) (uint32, uint32, error) {
+	sequence := uint32(wire.MaxTxInSequenceNum - 2)
+	if cfg.nonReplaceable {
+		sequence = wire.MaxTxInSequenceNum - 1
+	}
+
+	switch cfg.lockTime {
+	case LockTime.CurrentHeight:
+		return height, sequence, nil
+	case LockTime.AntiFeeSniping:
+		// same as Bitcoin Core: one time in ten, pick a height up to 99
+		// blocks back so delayed transactions do not stand out
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return 0, 0, err
+		}
+		if n.Int64() == 0 {
+			offset, err := rand.Int(rand.Reader, big.NewInt(100))
+			if err != nil {
+				return 0, 0, err
+			}
+			if uint32(offset.Int64()) > height {
+				return 0, sequence, nil
+			}
+			height -= uint32(offset.Int64())
+		}
+		return height, sequence, nil
+	case LockTime.None:
+		if cfg.nonReplaceable {
+			sequence = wire.MaxTxInSequenceNum
+		}
+		return 0, sequence, nil
+	case LockTime.Explicit:
+		if cfg.lockTimeValue < txscript.LockTimeThreshold {
+			if cfg.lockTimeValue > height {
+				return 0, 0, errors.New("locktime height is above the current block height")
+			}
+		} else if int64(cfg.lockTimeValue) > time.Now().Unix() {
+			return 0, 0, errors.New("locktime timestamp is in the future")
+		}
+		return cfg.lockTimeValue, sequence, nil
+	}
+	return 0, 0, errors.New("invalid locktime policy")
+}
+
+// orderOutputs arranges the outputs of an unsigned transaction according to
+// the configured strategy. Fixed keeps the change output at index 0 followed
+// by the recipients in the order they were given.
+func orderOutputs(tx *wire.MsgTx, order OutputOrderEnum) error {
+	switch order {
+	case OutputOrder.Fixed:
+	case OutputOrder.BIP69:
+		txsort.InPlaceSort(tx)
+	case OutputOrder.Random:
+		for i := len(tx.TxOut) - 1; i > 0; i-- {
+			j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+			if err != nil {
+				return err
+			}
+			tx.TxOut[i], tx.TxOut[j.Int64()] = tx.TxOut[j.Int64()], tx.TxOut[i]
+		}
+	default:
+		return errors.New("invalid output order")
+	}
+	return nil
+}
+
+func outputPosition(tx *wire.MsgTx, txOut *wire.TxOut) int {
+	for n, out := range tx.TxOut {
+		if out == txOut {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,124 @@
+package gosendcrypto
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+func testOutputsTx() (*wire.MsgTx, []*wire.TxOut) {
+	outs := []*wire.TxOut{
+		wire.NewTxOut(5000, []byte{0x02}),
+		wire.NewTxOut(1000, []byte{0x03}),
+		wire.NewTxOut(5000, []byte{0x01}),
+	}
+	tx := wire.NewMsgTx(2)
+	for _, out := range outs {
+		tx.AddTxOut(out)
+	}
+	return tx, outs
+}
+
+func TestOrderOutputs(t *testing.T) {
+	tx, outs := testOutputsTx()
+	if err := orderOutputs(tx, OutputOrder.Fixed); err != nil {
+		t.Fatal(err)
+	}
+	for n, out := range outs {
+		if got := outputPosition(tx, out); got != n {
+			t.Errorf("fixed: output %d moved to %d", n, got)
+		}
+	}
+
+	// BIP69 sorts by amount, then by script
+	tx, outs = testOutputsTx()
+	if err := orderOutputs(tx, OutputOrder.BIP69); err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range []int{2, 0, 1} {
+		if got := outputPosition(tx, outs[n]); got != want {
+			t.Errorf("bip69: output %d at %d, want %d", n, got, want)
+		}
+	}
+
+	tx, outs = testOutputsTx()
+	if err := orderOutputs(tx, OutputOrder.Random); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[int]bool{}
+	for _, out := range outs {
+		seen[outputPosition(tx, out)] = true
+	}
+	if len(seen) != len(outs) || seen[-1] {
+		t.Errorf("random: outputs lost, positions %v", seen)
+	}
+
+	if err := orderOutputs(tx, OutputOrderEnum("bogus")); err == nil {
+		t.Error("invalid order accepted")
+	}
+}
+
+func TestOutputPositionMissing(t *testing.T) {
+	tx, _ := testOutputsTx()
+	if got := outputPosition(tx, wire.NewTxOut(5000, []byte{0x02})); got != -1 {
+		t.Errorf("got %d for an output not in the tx", got)
+	}
+}
+
+func TestTxLockTime(t *testing.T) {
+	const height = 800000
+	final := uint32(wire.MaxTxInSequenceNum)
+	nonFinal := uint32(wire.MaxTxInSequenceNum - 1)
+	rbf := uint32(wire.MaxTxInSequenceNum - 2)
+
+	tests := []struct {
+		name     string
+		cfg      *CryptoSender
+		lockTime uint32
+		sequence uint32
+		wantErr  bool
+	}{
+		{"current height", &CryptoSender{}, height, rbf, false},
+		{"current height not replaceable", (&CryptoSender{}).SetReplaceable(false), height, nonFinal, false},
+		{"none", &CryptoSender{lockTime: LockTime.None}, 0, rbf, false},
+		{"none not replaceable", (&CryptoSender{lockTime: LockTime.None}).SetReplaceable(false), 0, final, false},
+		{"explicit height", (&CryptoSender{}).SetExplicitLockTime(height - 10), height - 10, rbf, false},
+		{"explicit future height", (&CryptoSender{}).SetExplicitLockTime(height + 1), 0, 0, true},
+		{"explicit timestamp", (&CryptoSender{}).SetExplicitLockTime(1600000000).SetReplaceable(false), 1600000000, nonFinal, false},
+		{"explicit future timestamp", (&CryptoSender{}).SetExplicitLockTime(4000000000), 0, 0, true},
+		{"invalid", &CryptoSender{lockTime: "bogus"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		lockTime, sequence, err := txLockTime(tt.cfg, height)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if lockTime != tt.lockTime || sequence != tt.sequence {
+			t.Errorf("%s: got locktime %d sequence %x, want %d %x", tt.name, lockTime, sequence, tt.lockTime, tt.sequence)
+		}
+	}
+}
+
+func TestTxLockTimeAntiFeeSniping(t *testing.T) {
+	const height = 800000
+	cfg := &CryptoSender{lockTime: LockTime.AntiFeeSniping}
+	for i := 0; i < 200; i++ {
+		lockTime, sequence, err := txLockTime(cfg, height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lockTime > height || lockTime < height-99 {
+			t.Fatalf("locktime %d outside [%d, %d]", lockTime, height-99, height)
+		}
+		if sequence != wire.MaxTxInSequenceNum-2 {
+			t.Fatalf("sequence %x does not signal replaceability", sequence)
+		}
+	}
+}
@@ -3,19 +3,71 @@ package gosendcrypto
 import (
 	"context"
 	"errors"
+	"math/big"
 	"strings"
+	"time"
 )
 
 type BlockchainEnum string
 
 var Blockchain = struct {
-	Ethereum BlockchainEnum
-	Tron     BlockchainEnum
-	Bitcoin  BlockchainEnum
+	Ethereum  BlockchainEnum
+	Tron      BlockchainEnum
+	Bitcoin   BlockchainEnum
+	Polygon   BlockchainEnum
+	BSC       BlockchainEnum
+	Arbitrum  BlockchainEnum
+	Base      BlockchainEnum
+	Avalanche BlockchainEnum
 }{
-	Ethereum: "ethereum",
-	Tron:     "tron",
-	Bitcoin:  "bitcoin",
+	Ethereum:  "ethereum",
+	Tron:      "tron",
+	Bitcoin:   "bitcoin",
+	Polygon:   "polygon",
+	BSC:       "bsc",
+	Arbitrum:  "arbitrum",
+	Base:      "base",
+	Avalanche: "avalanche",
+}
+
+type OutputOrderEnum string
+
+var OutputOrder = struct {
+	Fixed  OutputOrderEnum
+	BIP69  OutputOrderEnum
+	Random OutputOrderEnum
+}{
+	Fixed:  "",
+	BIP69:  "bip69",
+	Random: "random",
+}
+
+type LockTimeEnum string
+
+var LockTime = struct {
+	CurrentHeight  LockTimeEnum
+	AntiFeeSniping LockTimeEnum
+	None           LockTimeEnum
+	Explicit       LockTimeEnum
+}{
+	CurrentHeight:  "",
+	AntiFeeSniping: "antifeesniping",
+	None:           "none",
+	Explicit:       "explicit",
+}
+
+type TxTypeEnum string
+
+var TxType = struct {
+	Auto       TxTypeEnum
+	DynamicFee TxTypeEnum
+	Legacy     TxTypeEnum
+	AccessList TxTypeEnum
+}{
+	Auto:       "",
+	DynamicFee: "dynamicfee",
+	Legacy:     "legacy",
+	AccessList: "accesslist",
 }
 
 type NetworkEnum string
@@ -41,9 +93,18 @@ var addrPrefixNetwork = map[string]string{
 }
 
 var senders = map[BlockchainEnum]func(ctx context.Context, cfg *CryptoSender, privKey, to string, amount float64, addrValues ...*SendToManyObj) (*Result, error){
-	Blockchain.Ethereum: sendEthereum,
-	Blockchain.Bitcoin:  sendBitcoin,
-	Blockchain.Tron:     sendTron,
+	Blockchain.Ethereum:  sendEthereum,
+	Blockchain.Bitcoin:   sendBitcoin,
+	Blockchain.Tron:      sendTron,
+	Blockchain.Polygon:   sendEthereum,
+	Blockchain.BSC:       sendEthereum,
+	Blockchain.Arbitrum:  sendEthereum,
+	Blockchain.Base:      sendEthereum,
+	Blockchain.Avalanche: sendEthereum,
+}
+
+var txStatusCheckers = map[BlockchainEnum]func(ctx context.Context, cfg *CryptoSender, txHash string) (*TxStatus, error){
+	Blockchain.Bitcoin: bitcoinTxStatus,
 }
 
 func NewCryptoSender(blockchain BlockchainEnum, network NetworkEnum, gatewayURL string) *CryptoSender {
@@ -55,16 +116,38 @@ func NewCryptoSender(blockchain BlockchainEnum, network NetworkEnum, gatewayURL
 }
 
 type Result struct {
-	TxHash     string
-	TxPosition int
-	Nonce      uint64
-	Balance    float64
-	Data       string
+	TxHash          string
+	TxPosition      int
+	Nonce           uint64
+	Balance         float64
+	Data            string
+	OutputPositions []int
+	ChangePosition  int
+	GasLimit        uint64
+	SweptAmount     float64
+	BalanceDeltas   []*BalanceDelta
+	// set when the destination was an ENS name
+	ResolvedAddress string
+	// gas saved by the attached EIP-2930 access list
+	GasSaved uint64
+	// set for EVM sends when awaiting confirmation
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+	BlockNumber       uint64
+}
+
+type TxStatus struct {
+	TxHash        string
+	Confirmations int
+	BlockHeight   int
+	BlockHash     string
+	InMempool     bool
 }
 
 type SendToManyResult struct {
-	Success []*sendToManyResObj
-	Failed  []*sendToManyResObj
+	Success        []*sendToManyResObj
+	Failed         []*sendToManyResObj
+	ChangePosition int
 }
 
 type sendToManyResObj struct {
@@ -93,9 +176,30 @@ type CryptoSender struct {
 	hash              string
 	txPosition        int
 	balance           float64
-	nonce             uint64
+	nonce             *uint64
 	awaitConfirmation bool
 	tipBoost          float64
+	outputOrder       OutputOrderEnum
+	lockTime          LockTimeEnum
+	lockTimeValue     uint32
+	nonReplaceable    bool
+	confirmations     int
+	pollInterval      time.Duration
+	gasMultiplier     float64
+	gasLimitCap       uint64
+	feeStrategy       FeeStrategy
+	txType            TxTypeEnum
+	nonceManager      *NonceManager
+	multisendContract string
+	sweep             bool
+	simulate          bool
+	verifyReverseENS  bool
+	callData          string
+	callABI           string
+	callMethod        string
+	callArgs          []interface{}
+	accessList        bool
+	chainID           *big.Int
 }
 
 func (c *CryptoSender) SetAPIKey(apiKey string) *CryptoSender {
@@ -115,17 +219,129 @@ func (c *CryptoSender) SetBalance(balance float64) *CryptoSender {
 	return c
 }
 func (c *CryptoSender) SetNonce(nonce uint64) *CryptoSender {
-	c.nonce = nonce
+	c.nonce = &nonce
+	return c
+}
+func (c *CryptoSender) SetNonceManager(manager *NonceManager) *CryptoSender {
+	c.nonceManager = manager
 	return c
 }
 func (c *CryptoSender) SetTipBoost(tipBoost float64) *CryptoSender {
 	c.tipBoost = tipBoost
 	return c
 }
+func (c *CryptoSender) SetFeeStrategy(strategy FeeStrategy) *CryptoSender {
+	c.feeStrategy = strategy
+	return c
+}
+func (c *CryptoSender) SetTxType(txType TxTypeEnum) *CryptoSender {
+	c.txType = txType
+	return c
+}
+func (c *CryptoSender) SetGasMultiplier(multiplier float64) *CryptoSender {
+	c.gasMultiplier = multiplier
+	return c
+}
+func (c *CryptoSender) SetGasLimitCap(gasLimitCap uint64) *CryptoSender {
+	c.gasLimitCap = gasLimitCap
+	return c
+}
+
+// SetSweep makes native EVM sends ignore the amount and send the whole
+// balance minus the network fee, leaving the address empty. SendToMany
+// rejects it.
+func (c *CryptoSender) SetSweep(sweep bool) *CryptoSender {
+	c.sweep = sweep
+	return c
+}
+
+// SetSimulate runs EVM contract calls through eth_call before they are
+// broadcast, see simulateEthMsg.
+func (c *CryptoSender) SetSimulate(simulate bool) *CryptoSender {
+	c.simulate = simulate
+	return c
+}
+
+// SetAccessList attaches an access list from eth_createAccessList to EVM
+// transactions when it lowers their gas. Legacy transactions are sent
+// without one.
+func (c *CryptoSender) SetAccessList(accessList bool) *CryptoSender {
+	c.accessList = accessList
+	return c
+}
+
+// SetVerifyReverseENS requires an ENS destination's address to resolve back
+// to the same name before anything is sent.
+func (c *CryptoSender) SetVerifyReverseENS(verify bool) *CryptoSender {
+	c.verifyReverseENS = verify
+	return c
+}
 func (c *CryptoSender) SetAwaitConfirmation(wait bool) *CryptoSender {
 	c.awaitConfirmation = wait
 	return c
 }
+func (c *CryptoSender) SetConfirmations(confirmations int) *CryptoSender {
+	c.confirmations = confirmations
+	return c
+}
+func (c *CryptoSender) SetPollInterval(interval time.Duration) *CryptoSender {
+	c.pollInterval = interval
+	return c
+}
+func (c *CryptoSender) SetOutputOrder(order OutputOrderEnum) *CryptoSender {
+	c.outputOrder = order
+	return c
+}
+func (c *CryptoSender) SetLockTime(policy LockTimeEnum) *CryptoSender {
+	c.lockTime = policy
+	return c
+}
+
+// SetReplaceable controls whether Bitcoin inputs signal BIP-125 opt-in
+// replace-by-fee. Inputs are replaceable by default.
+func (c *CryptoSender) SetReplaceable(replaceable bool) *CryptoSender {
+	c.nonReplaceable = !replaceable
+	return c
+}
+
+// SetExplicitLockTime pins nLockTime to a block height, or to a unix
+// timestamp when the value is at least 500000000.
+func (c *CryptoSender) SetExplicitLockTime(lockTime uint32) *CryptoSender {
+	c.lockTime = LockTime.Explicit
+	c.lockTimeValue = lockTime
+	return c
+}
+
+// SetMultisendContract routes EVM SendToMany batches through a disperse
+// contract (disperse.app compatible) as a single transaction.
+func (c *CryptoSender) SetMultisendContract(contractAddr string) *CryptoSender {
+	c.multisendContract = contractAddr
+	return c
+}
+
+// SetCallData sends raw hex calldata with EVM transfers, turning them into
+// contract calls on the destination address. The amount is sent as value.
+func (c *CryptoSender) SetCallData(data string) *CryptoSender {
+	c.callData = data
+	return c
+}
+
+// SetContractCall is SetCallData with the calldata packed from a JSON ABI,
+// method name and arguments. Arguments use go-ethereum types, e.g.
+// common.Address and *big.Int.
+func (c *CryptoSender) SetContractCall(abiJSON, method string, args ...interface{}) *CryptoSender {
+	c.callABI = abiJSON
+	c.callMethod = method
+	c.callArgs = args
+	return c
+}
+
+// SetChainID pins the chain ID the EVM gateway must report. Without it only
+// chain IDs of the known EVM chains are checked against the blockchain.
+func (c *CryptoSender) SetChainID(chainID int64) *CryptoSender {
+	c.chainID = big.NewInt(chainID)
+	return c
+}
 func (c *CryptoSender) SetContractAddress(contractAddr string) *CryptoSender {
 	c.contractAddr = contractAddr
 	return c
@@ -143,6 +359,14 @@ func (c *CryptoSender) Sendcrypto(ctx context.Context, privateKey, toAddress str
 	if c.blockchain == Blockchain.Bitcoin {
 		networkCheck += string(c.network)
 	}
+	if isEVM(c.blockchain) {
+		// every EVM chain shares the ethereum address format
+		networkCheck = string(Blockchain.Ethereum)
+	}
+	if c.blockchain == Blockchain.Ethereum && isENSName(toAddress) {
+		// resolved by sendEthereum
+		net = networkCheck
+	}
 
 	if net != networkCheck {
 		return nil, errors.New("invalid network or toAddress")
@@ -153,10 +377,22 @@ func (c *CryptoSender) Sendcrypto(ctx context.Context, privateKey, toAddress str
 	return
 }
 
+func (c *CryptoSender) TxStatus(ctx context.Context, txHash string) (*TxStatus, error) {
+	checker, ok := txStatusCheckers[c.blockchain]
+	if !ok {
+		return nil, errors.New("tx status not supported for " + string(c.blockchain))
+	}
+	return checker(ctx, c, txHash)
+}
+
 func (c *CryptoSender) SendToMany(ctx context.Context, privateKey string, addrValues []*SendToManyObj) (res *SendToManyResult, err error) {
 	if len(addrValues) < 1 {
 		return nil, errors.New("invalid addrValues length")
 	}
+	// the first recipient would empty the address and leave nothing for the rest
+	if c.sweep {
+		return nil, errors.New("sweep is not supported by SendToMany")
+	}
 	toAddress := addrValues[0].Address
 	net := ""
 	prefix := ""
@@ -170,11 +406,19 @@ func (c *CryptoSender) SendToMany(ctx context.Context, privateKey string, addrVa
 	if c.blockchain == Blockchain.Bitcoin {
 		networkCheck += string(c.network)
 	}
+	if isEVM(c.blockchain) {
+		// every EVM chain shares the ethereum address format
+		networkCheck = string(Blockchain.Ethereum)
+	}
 
 	if net != networkCheck {
 		return nil, errors.New("invalid network or toAddress")
 	}
 
+	if isEVM(c.blockchain) && c.multisendContract != "" {
+		return c.sendMultisend(ctx, privateKey, addrValues)
+	}
+
 	sender := senders[c.blockchain]
 	res = &SendToManyResult{
 		Success: []*sendToManyResObj{},
@@ -182,7 +426,7 @@ func (c *CryptoSender) SendToMany(ctx context.Context, privateKey string, addrVa
 	}
 	if c.blockchain == Blockchain.Bitcoin {
 		result, err := sender(ctx, c, privateKey, "", 0, addrValues...)
-		if err != nil {
+		if result == nil {
 			return res, err
 		}
 		resList := []*sendToManyResObj{}
@@ -193,35 +437,58 @@ func (c *CryptoSender) SendToMany(ctx context.Context, privateKey string, addrVa
 			resList = append(resList, &sendToManyResObj{
 				Address:    addrVal.Address,
 				Amount:     addrVal.Amount,
-				TxPosition: n + 1,
+				TxPosition: result.OutputPositions[n],
 				TxHash:     result.TxHash,
 			})
 		}
 		res.Success = resList
+		res.ChangePosition = result.ChangePosition
+		if err != nil {
+			// broadcast, but waiting for confirmations failed
+			return res, err
+		}
 	} else {
-		nonce := c.nonce
+		// work on a copy so concurrent sends sharing c never see each
+		// other's nonces
+		cfg := *c
 		for _, addrVal := range addrValues {
-			c.nonce = nonce
-			result, err := sender(ctx, c, privateKey, addrVal.Address, addrVal.Amount)
+			result, err := sender(ctx, &cfg, privateKey, addrVal.Address, addrVal.Amount)
 			if err != nil {
-				res.Failed = append(res.Failed, &sendToManyResObj{
+				failed := &sendToManyResObj{
 					Address: addrVal.Address,
 					Amount:  addrVal.Amount,
 					Err:     err,
-				})
+				}
+				if result != nil {
+					failed.Nonce = result.Nonce
+					failed.TxHash = result.TxHash
+					failed.TxData = result.Data
+				}
+				res.Failed = append(res.Failed, failed)
 				if addrVal.TerminateOnFail {
 					return res, err
 				}
-				continue
+				if result == nil {
+					continue
+				}
+			} else {
+				res.Success = append(res.Success, &sendToManyResObj{
+					Address: addrVal.Address,
+					Amount:  addrVal.Amount,
+					Nonce:   result.Nonce,
+					TxHash:  result.TxHash,
+					TxData:  result.Data,
+				})
+			}
+			// a broadcast tx used its nonce even if it reverted
+			if cfg.nonceManager != nil {
+				// an explicit nonce only applies to the first tx, the
+				// manager takes over from there
+				cfg.nonce = nil
+			} else {
+				nonce := result.Nonce + 1
+				cfg.nonce = &nonce
 			}
-			res.Success = append(res.Success, &sendToManyResObj{
-				Address: addrVal.Address,
-				Amount:  addrVal.Amount,
-				Nonce:   result.Nonce,
-				TxHash:  result.TxHash,
-				TxData:  result.Data,
-			})
-			nonce = result.Nonce + 1
 		}
 	}
 
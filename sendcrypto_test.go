@@ -0,0 +1,18 @@
+package gosendcrypto
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSendToManyRejectsSweep(t *testing.T) {
+	recipients := []*SendToManyObj{{Address: testAccount.Hex(), Amount: 1}}
+	for _, multisend := range []string{"", testAccount.Hex()} {
+		c := NewCryptoSender(Blockchain.Ethereum, "", "").SetSweep(true)
+		c.multisendContract = multisend
+		_, err := c.SendToMany(context.Background(), "", recipients)
+		if err == nil || err.Error() != "sweep is not supported by SendToMany" {
+			t.Errorf("multisend %q: got %v, want the sweep error", multisend, err)
+		}
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"log"
 	"math/big"
 
 	"github.com/payourse/gosendcrypto/erc20"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -18,110 +20,212 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func sendEthereum(ctx context.Context, cfg *CryptoSender, privKey, to string, value float64, addrValues ...*SendToManyObj) (*Result, error) {
-	client, err := ethclient.Dial(cfg.gateway)
-	if err != nil {
-		return nil, err
-	}
+const defaultGasMultiplier = 1.2
 
-	pk, err := crypto.ToECDSA(common.FromHex(privKey))
+// native amounts are converted to wei like an 18 decimal token
+const etherDecimals = 18
+
+func sendEthereum(ctx context.Context, cfg *CryptoSender, privKey, to string, value float64, addrValues ...*SendToManyObj) (*Result, error) {
+	client, pk, networkID, err := dialEthereum(ctx, cfg, privKey)
 	if err != nil {
 		return nil, err
 	}
 
 	fromAddress := crypto.PubkeyToAddress(pk.PublicKey)
-	toAddress := common.HexToAddress(to)
-	gasLimit := uint64(21000)
-
-	balance, err := client.BalanceAt(ctx, fromAddress, nil)
+	toAddress, err := ethDestination(ctx, cfg, client, to)
 	if err != nil {
 		return nil, err
 	}
 
-	// amount := big.NewInt(int64(value * params.Ether))
-	amountFloat := new(big.Float).Mul(big.NewFloat(value), big.NewFloat(params.Ether))
-	amount, ok := new(big.Int).SetString(amountFloat.Text('f', 0), 10)
-
-	if !ok {
-		return nil, errors.New("error converting value to ether")
-	}
-
-	networkID, err := client.NetworkID(ctx)
+	data, err := ethCallData(cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	nonce := cfg.nonce
-	if nonce == 0 {
-		nonce, err = client.NonceAt(ctx, fromAddress, nil)
-		if err != nil {
-			return nil, err
-		}
+	if len(data) > 0 && (cfg.contractAddr != "" || cfg.sweep) {
+		return nil, errors.New("call data cannot be combined with token transfers or sweep")
 	}
 
-	feeCap, err := client.SuggestGasPrice(ctx)
-	if err != nil {
-		return nil, err
+	if cfg.sweep && cfg.contractAddr != "" {
+		return nil, errors.New("sweep is only supported for native transfers")
 	}
 
-	tip, err := client.SuggestGasTipCap(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if cfg.tipBoost > 0 {
-		tip = new(big.Int).Add(tip, big.NewInt(int64(cfg.tipBoost*float64(tip.Int64()))))
-	}
-
-	var tx *types.Transaction
+	var msg ethereum.CallMsg
 
 	if cfg.contractAddr != "" {
-		tx, err = senderc20Token(
+		msg, err = erc20TransferMsg(
 			client,
-			pk,
 			common.HexToAddress(cfg.contractAddr),
 			fromAddress,
 			toAddress,
-			networkID,
-			tip,
-			feeCap,
-			big.NewInt(int64(nonce)),
 			value,
 		)
 		if err != nil {
 			return nil, err
 		}
+	} else if cfg.sweep {
+		// the amount is worked out in sendEthMsg once the fees are known
+		msg = ethereum.CallMsg{
+			From:  fromAddress,
+			To:    &toAddress,
+			Value: big.NewInt(0),
+			Data:  []byte{},
+		}
 	} else {
-		tx = types.NewTx(&types.DynamicFeeTx{
-			ChainID:   networkID,
-			Nonce:     nonce,
-			GasFeeCap: feeCap,
-			GasTipCap: tip,
-			Gas:       gasLimit,
-			To:        &toAddress,
-			Value:     amount,
-			Data:      []byte{},
-		})
-		tx, err = types.SignTx(tx, types.LatestSignerForChainID(networkID), pk)
+		balance, err := client.BalanceAt(ctx, fromAddress, nil)
 		if err != nil {
 			return nil, err
 		}
+
+		amount, err := tokenUnits(value, etherDecimals)
+		if err != nil {
+			return nil, err
+		}
+
 		if balance.Cmp(amount) != 1 {
 			return nil, errors.New("amount should be less than balance")
 		}
 
-		err = client.SendTransaction(ctx, tx)
+		msg = ethereum.CallMsg{
+			From:  fromAddress,
+			To:    &toAddress,
+			Value: amount,
+			Data:  data,
+		}
+	}
+
+	result, err := sendEthMsg(ctx, cfg, client, pk, networkID, msg)
+	if result != nil && isENSName(to) {
+		result.ResolvedAddress = toAddress.Hex()
+	}
+	return result, err
+}
+
+// dialEthereum connects to the gateway, parses the private key and makes sure
+// the gateway serves the sender's chain.
+func dialEthereum(ctx context.Context, cfg *CryptoSender, privKey string) (*ethclient.Client, *ecdsa.PrivateKey, *big.Int, error) {
+	client, err := ethclient.Dial(cfg.gateway)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pk, err := crypto.ToECDSA(common.FromHex(privKey))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	networkID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = checkChainID(cfg, networkID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return client, pk, networkID, nil
+}
+
+// sendEthMsg prices msg with the sender's tx type and fee strategy, estimates
+// its gas, assigns a nonce, then signs and broadcasts it.
+func sendEthMsg(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, pk *ecdsa.PrivateKey, networkID *big.Int, msg ethereum.CallMsg) (*Result, error) {
+	fromAddress := crypto.PubkeyToAddress(pk.PublicKey)
+
+	txType, err := resolveTxType(ctx, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := ethFees(ctx, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if txType == TxType.DynamicFee {
+		msg.GasFeeCap = fees.FeeCap
+		msg.GasTipCap = fees.TipCap
+	} else {
+		msg.GasPrice = fees.GasPrice()
+	}
+	var deltas []*BalanceDelta
+	if cfg.simulate && len(msg.Data) > 0 {
+		deltas, err = simulateEthMsg(ctx, client, msg)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if cfg.awaitConfirmation {
-		_, err := bind.WaitMined(ctx, client, tx)
+	var gasSaved uint64
+	if cfg.accessList && txType != TxType.Legacy {
+		list, saved, err := optimizeAccessList(ctx, client, msg)
+		if err != nil {
+			// the list is only an optimisation, so send without it
+			log.Println("access list skipped:", err.Error())
+		} else {
+			msg.AccessList = list
+			gasSaved = saved
+		}
+	}
+
+	gasLimit, err := estimateGasLimit(ctx, cfg, client, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.sweep && len(msg.Data) == 0 {
+		msg.Value, err = sweepValue(ctx, cfg, client, txType, networkID, gasLimit, fees, msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var reservation *NonceReservation
+	var nonce uint64
+	if cfg.nonce != nil {
+		nonce = *cfg.nonce
+	} else if cfg.nonceManager != nil {
+		reservation, err = cfg.nonceManager.Reserve(ctx, client, networkID, fromAddress)
+		if err != nil {
+			return nil, err
+		}
+		defer reservation.Release()
+		nonce = reservation.Nonce
+	} else {
+		nonce, err = client.PendingNonceAt(ctx, fromAddress)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	tx := newEthTx(txType, networkID, nonce, gasLimit, fees, msg)
+	tx, err = types.SignTx(tx, types.LatestSignerForChainID(networkID), pk)
+	if err != nil {
+		return nil, err
+	}
+
+	// a result with an error is a broadcast tx that reverted or could not
+	// be confirmed, keep it so the caller sees the hash and nonce
+	result, err := broadcastEthTx(ctx, cfg, client, tx, reservation)
+	if result == nil {
+		return nil, err
+	}
+	result.BalanceDeltas = deltas
+	result.GasSaved = gasSaved
+	if cfg.sweep && len(msg.Data) == 0 {
+		result.SweptAmount, _ = new(big.Float).Quo(new(big.Float).SetInt(msg.Value), big.NewFloat(params.Ether)).Float64()
+	}
+	return result, err
+}
+
+// broadcastEthTx sends a signed transaction, waits for it to be mined when
+// the sender awaits confirmation and reports it as a Result. The nonce
+// reservation, if any, is committed as soon as the node accepts the tx.
+func broadcastEthTx(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, tx *types.Transaction, reservation *NonceReservation) (*Result, error) {
+	err := client.SendTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	reservation.Commit()
+
 	dataStr := ""
 	data, err := tx.MarshalBinary()
 	if err != nil {
@@ -131,69 +235,225 @@ func sendEthereum(ctx context.Context, cfg *CryptoSender, privKey, to string, va
 	}
 
 	result := &Result{
-		TxHash: tx.Hash().Hex(),
-		Nonce:  nonce,
-		Data:   dataStr,
+		TxHash:   tx.Hash().Hex(),
+		Nonce:    tx.Nonce(),
+		Data:     dataStr,
+		GasLimit: tx.Gas(),
+	}
+
+	if cfg.awaitConfirmation {
+		receipt, err := bind.WaitMined(ctx, client, tx)
+		if err != nil {
+			return result, err
+		}
+
+		result.GasUsed = receipt.GasUsed
+		result.EffectiveGasPrice = receipt.EffectiveGasPrice
+		result.BlockNumber = receipt.BlockNumber.Uint64()
+
+		// the hash is still returned so a reverted tx can be traced
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return result, fmt.Errorf("transaction %s reverted in block %d", result.TxHash, result.BlockNumber)
+		}
+
+		// only the configured token is checked, other contracts such as
+		// ERC721 share the transferFrom selector
+		if cfg.contractAddr != "" && tx.To() != nil && *tx.To() == common.HexToAddress(cfg.contractAddr) {
+			err = verifyTokenTransfer(tx, receipt)
+			if err != nil {
+				return result, err
+			}
+		}
 	}
 	return result, nil
+}
+
+// verifyTokenTransfer checks that a mined erc20 transfer or transferFrom
+// emitted a Transfer log paying the recipient the full amount. Tokens that
+// return false instead of reverting, or take a fee on transfer, fail here.
+func verifyTokenTransfer(tx *types.Transaction, receipt *types.Receipt) error {
+	if tx.To() == nil || len(tx.Data()) < 4 {
+		return nil
+	}
 
+	erc20ABI, err := erc20.Erc20MetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+
+	method, err := erc20ABI.MethodById(tx.Data()[:4])
+	if err != nil || (method.Name != "transfer" && method.Name != "transferFrom") {
+		return nil
+	}
+
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return err
+	}
+	recipient := args[len(args)-2].(common.Address)
+	amount := args[len(args)-1].(*big.Int)
+
+	filterer, err := erc20.NewErc20Filterer(*tx.To(), nil)
+	if err != nil {
+		return err
+	}
+
+	received := big.NewInt(0)
+	found := false
+	for _, log := range receipt.Logs {
+		if log.Address != *tx.To() {
+			continue
+		}
+		event, err := filterer.ParseTransfer(*log)
+		if err != nil || event.To != recipient {
+			continue
+		}
+		found = true
+		received.Add(received, event.Value)
+	}
+
+	if !found {
+		return fmt.Errorf("transaction %s emitted no Transfer to %s", tx.Hash().Hex(), recipient.Hex())
+	}
+	if received.Cmp(amount) != 0 {
+		return fmt.Errorf("transaction %s transferred %s of %s units to %s", tx.Hash().Hex(), received.String(), amount.String(), recipient.Hex())
+	}
+	return nil
 }
 
-func senderc20Token(client *ethclient.Client, privKey *ecdsa.PrivateKey, contractAddr, fromAddr, toAddr common.Address, networkID, tip, feeCap, nonce *big.Int, value float64) (*types.Transaction, error) {
-	auth, err := bind.NewKeyedTransactorWithChainID(privKey, networkID)
+// resolveTxType picks the envelope for the next transaction. With TxType.Auto
+// chains whose latest block carries no base fee get legacy transactions.
+func resolveTxType(ctx context.Context, cfg *CryptoSender, client *ethclient.Client) (TxTypeEnum, error) {
+	switch cfg.txType {
+	case TxType.DynamicFee, TxType.Legacy, TxType.AccessList:
+		return cfg.txType, nil
+	case TxType.Auto:
+		if info, ok := evmChains[cfg.blockchain]; ok && info.DefaultTxType != TxType.Auto {
+			return info.DefaultTxType, nil
+		}
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		if head.BaseFee == nil {
+			return TxType.Legacy, nil
+		}
+		return TxType.DynamicFee, nil
+	}
+	return "", errors.New("invalid tx type")
+}
+
+// newEthTx builds an unsigned transaction of the given type. Legacy and
+// access list transactions pay Fees.GasPrice and are signed with EIP-155
+// replay protection by types.LatestSignerForChainID.
+func newEthTx(txType TxTypeEnum, chainID *big.Int, nonce, gasLimit uint64, fees *Fees, msg ethereum.CallMsg) *types.Transaction {
+	switch txType {
+	case TxType.Legacy:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: fees.GasPrice(),
+			Gas:      gasLimit,
+			To:       msg.To,
+			Value:    msg.Value,
+			Data:     msg.Data,
+		})
+	case TxType.AccessList:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasPrice:   fees.GasPrice(),
+			Gas:        gasLimit,
+			To:         msg.To,
+			Value:      msg.Value,
+			Data:       msg.Data,
+			AccessList: msg.AccessList,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		GasFeeCap:  fees.FeeCap,
+		GasTipCap:  fees.TipCap,
+		Gas:        gasLimit,
+		To:         msg.To,
+		Value:      msg.Value,
+		Data:       msg.Data,
+		AccessList: msg.AccessList,
+	})
+}
+
+// estimateGasLimit asks the node for the gas msg needs and adds the
+// configured safety margin. Plain transfers to accounts without code cost
+// exactly params.TxGas, so no margin is added to those.
+func estimateGasLimit(ctx context.Context, cfg *CryptoSender, client *ethclient.Client, msg ethereum.CallMsg) (uint64, error) {
+	gas, err := client.EstimateGas(ctx, msg)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+
+	gasLimit := gas
+	if gas > params.TxGas {
+		multiplier := cfg.gasMultiplier
+		if multiplier <= 0 {
+			multiplier = defaultGasMultiplier
+		}
+		gasLimit = uint64(float64(gas) * multiplier)
 	}
 
+	if cfg.gasLimitCap > 0 && gasLimit > cfg.gasLimitCap {
+		if gas > cfg.gasLimitCap {
+			return 0, fmt.Errorf("estimated gas %d exceeds gas limit cap %d", gas, cfg.gasLimitCap)
+		}
+		gasLimit = cfg.gasLimitCap
+	}
+	return gasLimit, nil
+}
+
+func erc20TransferMsg(client *ethclient.Client, contractAddr, fromAddr, toAddr common.Address, value float64) (ethereum.CallMsg, error) {
 	callOpts := &bind.CallOpts{
 		Pending: false,
 		From:    fromAddr,
 	}
 
-	contract, err := erc20.NewErc20(contractAddr, client)
+	contract, err := erc20.NewErc20Caller(contractAddr, client)
 	if err != nil {
-		return nil, err
+		return ethereum.CallMsg{}, err
 	}
 
 	balance, err := contract.BalanceOf(callOpts, fromAddr)
 	if err != nil {
-		return nil, err
+		return ethereum.CallMsg{}, err
 	}
 
 	decimals, err := contract.Decimals(callOpts)
 	if err != nil {
-		return nil, err
+		return ethereum.CallMsg{}, err
 	}
 
-	// amount := big.NewInt(int64(value * math.Pow10(int(decimals))))
-	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-	amountFloat := new(big.Float).Mul(big.NewFloat(value), new(big.Float).SetInt(multiplier))
-	amount, ok := new(big.Int).SetString(amountFloat.Text('f', 0), 10)
-	if !ok {
-		return nil, errors.New("error converting value to unit")
+	amount, err := tokenUnits(value, decimals)
+	if err != nil {
+		return ethereum.CallMsg{}, err
 	}
 
 	if balance.Cmp(amount) == -1 {
-		return nil, errors.New("value should be equal or greater than balance")
+		return ethereum.CallMsg{}, errors.New("value should be equal or greater than balance")
 	}
 
-	if tip.String() == "0" {
-		tip = new(big.Int).Add(tip, big.NewInt(1_000_000_000))
-	}
-
-	feeCap2 := new(big.Int).Mul(feeCap, big.NewInt(2))
-	auth.GasTipCap = tip
-	auth.GasFeeCap = feeCap2
-	auth.From = fromAddr
-	auth.Nonce = nonce
+	return erc20Msg(fromAddr, contractAddr, "transfer", toAddr, amount)
+}
 
-	tx, err := contract.Transfer(auth, toAddr, amount)
-	if err != nil {
-		fmt.Println("erc20 transfer err:", err)
-		return nil, err
+func tokenUnits(value float64, decimals uint8) (*big.Int, error) {
+	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amountFloat := new(big.Float).Mul(big.NewFloat(value), new(big.Float).SetInt(multiplier))
+	amount, ok := new(big.Int).SetString(amountFloat.Text('f', 0), 10)
+	if !ok {
+		return nil, errors.New("error converting value to unit")
 	}
+	return amount, nil
+}
 
-	fmt.Println("nonce", tx.Nonce(), "feecap", tx.GasFeeCap().String(), "tip", tx.GasTipCap().String())
-
-	return tx, nil
+func tokenValue(amount *big.Int, decimals uint8) float64 {
+	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(multiplier)).Float64()
+	return value
 }
@@ -0,0 +1,92 @@
+package gosendcrypto
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignMessage signs message with the sender's key type to prove ownership of
+// an address. EVM chains produce a hex EIP-191 personal_sign signature,
+// Bitcoin a base64 BIP-137 signature for the key's P2WPKH address.
+func (c *CryptoSender) SignMessage(privateKey, message string) (string, error) {
+	if isEVM(c.blockchain) {
+		return signEthMessage(privateKey, []byte(message))
+	}
+	if c.blockchain == Blockchain.Bitcoin {
+		return signBitcoinMessage(privateKey, message)
+	}
+	return "", errors.New("message signing not supported for " + string(c.blockchain))
+}
+
+// VerifyMessage checks that signature was made over message by the key
+// behind address. Bitcoin accepts both BIP-137 and BIP-322 simple
+// signatures.
+func (c *CryptoSender) VerifyMessage(address, message, signature string) (bool, error) {
+	if isEVM(c.blockchain) {
+		return verifyEthMessage(address, []byte(message), signature)
+	}
+	if c.blockchain == Blockchain.Bitcoin {
+		return c.verifyBitcoinMessage(address, message, signature)
+	}
+	return false, errors.New("message verification not supported for " + string(c.blockchain))
+}
+
+// SignTypedData signs EIP-712 typed data, returning the hex signature.
+func (c *CryptoSender) SignTypedData(privateKey string, typedData apitypes.TypedData) (string, error) {
+	if !isEVM(c.blockchain) {
+		return "", errors.New("typed data signing is only supported on EVM chains")
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return "", err
+	}
+	return signEthHash(privateKey, hash)
+}
+
+func signEthMessage(privateKey string, message []byte) (string, error) {
+	return signEthHash(privateKey, accounts.TextHash(message))
+}
+
+func signEthHash(privateKey string, hash []byte) (string, error) {
+	pk, err := crypto.ToECDSA(common.FromHex(privateKey))
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := crypto.Sign(hash, pk)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig), nil
+}
+
+func verifyEthMessage(address string, message []byte, signature string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, errors.New("invalid address: " + address)
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return false, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return false, errors.New("invalid signature length")
+	}
+	// wallets use both 0/1 and 27/28 for v
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return false, err
+	}
+	return crypto.PubkeyToAddress(*pub) == common.HexToAddress(address), nil
+}
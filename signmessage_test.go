@@ -0,0 +1,83 @@
+package gosendcrypto
+
+import "testing"
+
+// BIP-322 test vectors
+const (
+	testBIP322Address = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	testBIP322WIF     = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+)
+
+func TestVerifyBIP322Vectors(t *testing.T) {
+	c := NewCryptoSender(Blockchain.Bitcoin, Network.Mainnet, "")
+	tests := []struct {
+		message   string
+		signature string
+	}{
+		{"", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{"Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+	}
+	for _, tt := range tests {
+		ok, err := c.VerifyMessage(testBIP322Address, tt.message, tt.signature)
+		if err != nil || !ok {
+			t.Errorf("%q: got %v %v, want valid", tt.message, ok, err)
+		}
+	}
+
+	ok, err := c.VerifyMessage(testBIP322Address, "Hello World!", tests[1].signature)
+	if err != nil || ok {
+		t.Errorf("tampered message: got %v %v, want invalid", ok, err)
+	}
+}
+
+func TestBitcoinSignVerifyRoundTrip(t *testing.T) {
+	c := NewCryptoSender(Blockchain.Bitcoin, Network.Mainnet, "")
+	const message = "Hello World"
+
+	bip137, err := c.SignMessage(testBIP322WIF, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bip322, err := c.SignMessageBIP322(testBIP322WIF, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, signature := range map[string]string{"bip137": bip137, "bip322": bip322} {
+		ok, err := c.VerifyMessage(testBIP322Address, message, signature)
+		if err != nil || !ok {
+			t.Errorf("%s: got %v %v, want valid", name, ok, err)
+		}
+		ok, err = c.VerifyMessage("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", message, signature)
+		if err != nil || ok {
+			t.Errorf("%s for another address: got %v %v, want invalid", name, ok, err)
+		}
+	}
+}
+
+func TestEthSignVerifyMessage(t *testing.T) {
+	c := NewCryptoSender(Blockchain.Ethereum, Network.Mainnet, "")
+	const (
+		privateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+		address    = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+		// web3.eth.accounts.sign("Some data", privateKey)
+		want = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+	)
+
+	signature, err := c.SignMessage(privateKey, "Some data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature != want {
+		t.Errorf("got %s, want %s", signature, want)
+	}
+
+	ok, err := c.VerifyMessage(address, "Some data", signature)
+	if err != nil || !ok {
+		t.Errorf("got %v %v, want valid", ok, err)
+	}
+	ok, err = c.VerifyMessage(address, "Other data", signature)
+	if err != nil || ok {
+		t.Errorf("other message: got %v %v, want invalid", ok, err)
+	}
+}